@@ -0,0 +1,215 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Cloud providers supported by ResourceConfig.CloudProvider.
+const (
+	CloudProviderAWS   = "aws"
+	CloudProviderGCP   = "gcp"
+	CloudProviderAzure = "azure"
+)
+
+const (
+	// defaultCloudMetadataEndpoint is the link-local instance metadata address shared by AWS, GCP and Azure.
+	defaultCloudMetadataEndpoint = "http://169.254.169.254"
+	// defaultCloudDetectionTimeout bounds the metadata queries so an absent endpoint does not hang Init.
+	defaultCloudDetectionTimeout = 2 * time.Second
+)
+
+// metadataClient queries the instance metadata services. Unlike http.DefaultClient it ignores
+// HTTP_PROXY and HTTPS_PROXY, as the link-local metadata endpoint is only reachable directly.
+var metadataClient = &http.Client{Transport: &http.Transport{Proxy: nil}}
+
+// cloudDetector sets cloud.provider, cloud.platform, cloud.region, cloud.availability_zone and
+// cloud.account.id from the instance metadata service of the given provider.
+type cloudDetector struct {
+	provider string
+	endpoint string
+	timeout  time.Duration
+}
+
+// newCloudDetector returns a detector for the provider, applying the default endpoint and timeout.
+func newCloudDetector(config ResourceConfig) (cloudDetector, error) {
+	switch config.CloudProvider {
+	case CloudProviderAWS, CloudProviderGCP, CloudProviderAzure:
+	default:
+		return cloudDetector{}, fmt.Errorf("unsupported cloud provider %q", config.CloudProvider)
+	}
+
+	detector := cloudDetector{
+		provider: config.CloudProvider,
+		endpoint: strings.TrimSuffix(config.CloudMetadataEndpoint, "/"),
+		timeout:  config.CloudDetectionTimeout,
+	}
+	if detector.endpoint == "" {
+		detector.endpoint = defaultCloudMetadataEndpoint
+	}
+	if detector.timeout <= 0 {
+		detector.timeout = defaultCloudDetectionTimeout
+	}
+
+	return detector, nil
+}
+
+// Detect implements resource.Detector. An unreachable or slow metadata service does not fail
+// resource creation: the error is reported through the global OpenTelemetry error handler and no
+// cloud attributes are added, as the application may simply not run in that cloud.
+func (d cloudDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	var attrs []attribute.KeyValue
+	var err error
+
+	switch d.provider {
+	case CloudProviderAWS:
+		attrs, err = d.detectAWS(ctx)
+	case CloudProviderGCP:
+		attrs, err = d.detectGCP(ctx)
+	case CloudProviderAzure:
+		attrs, err = d.detectAzure(ctx)
+	}
+
+	if err != nil {
+		otel.Handle(fmt.Errorf("%s cloud detection: %w", d.provider, err))
+		return resource.Empty(), nil
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// detectAWS reads the EC2 instance identity document using an IMDSv2 session token.
+func (d cloudDetector) detectAWS(ctx context.Context) ([]attribute.KeyValue, error) {
+	token, err := d.get(ctx, http.MethodPut, "/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := d.get(ctx, http.MethodGet, "/latest/dynamic/instance-identity/document", map[string]string{
+		"X-aws-ec2-metadata-token": string(token),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	document := struct {
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		AccountID        string `json:"accountId"`
+	}{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("decoding instance identity document: %w", err)
+	}
+
+	return []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSEC2,
+		semconv.CloudRegion(document.Region),
+		semconv.CloudAvailabilityZone(document.AvailabilityZone),
+		semconv.CloudAccountID(document.AccountID),
+	}, nil
+}
+
+// detectGCP reads the project and zone of a Compute Engine instance, deriving the region from the zone.
+func (d cloudDetector) detectGCP(ctx context.Context) ([]attribute.KeyValue, error) {
+	headers := map[string]string{"Metadata-Flavor": "Google"}
+
+	project, err := d.get(ctx, http.MethodGet, "/computeMetadata/v1/project/project-id", headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// The zone is returned as projects/<number>/zones/<zone>.
+	zonePath, err := d.get(ctx, http.MethodGet, "/computeMetadata/v1/instance/zone", headers)
+	if err != nil {
+		return nil, err
+	}
+	zone := string(zonePath[strings.LastIndex(string(zonePath), "/")+1:])
+
+	region := zone
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+
+	return []attribute.KeyValue{
+		semconv.CloudProviderGCP,
+		semconv.CloudPlatformGCPComputeEngine,
+		semconv.CloudRegion(region),
+		semconv.CloudAvailabilityZone(zone),
+		semconv.CloudAccountID(string(project)),
+	}, nil
+}
+
+// detectAzure reads the compute metadata of an Azure virtual machine.
+func (d cloudDetector) detectAzure(ctx context.Context) ([]attribute.KeyValue, error) {
+	body, err := d.get(ctx, http.MethodGet, "/metadata/instance/compute?api-version=2021-12-13&format=json", map[string]string{
+		"Metadata": "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	compute := struct {
+		Location       string `json:"location"`
+		Zone           string `json:"zone"`
+		SubscriptionID string `json:"subscriptionId"`
+	}{}
+	if err := json.Unmarshal(body, &compute); err != nil {
+		return nil, fmt.Errorf("decoding compute metadata: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAzure,
+		semconv.CloudPlatformAzureVM,
+		semconv.CloudRegion(compute.Location),
+		semconv.CloudAccountID(compute.SubscriptionID),
+	}
+	if compute.Zone != "" {
+		attrs = append(attrs, semconv.CloudAvailabilityZone(compute.Zone))
+	}
+
+	return attrs, nil
+}
+
+// get sends a metadata request and returns the trimmed response body.
+func (d cloudDetector) get(ctx context.Context, method, path string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, d.endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+
+	return []byte(strings.TrimSpace(string(body))), nil
+}
@@ -0,0 +1,135 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// metadataServer stubs the instance metadata endpoints of AWS, GCP and Azure.
+func metadataServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /latest/api/token", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("token\n"))
+	})
+	mux.HandleFunc("GET /latest/dynamic/instance-identity/document", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"region":"eu-west-1","availabilityZone":"eu-west-1a","accountId":"123456789012"}`))
+	})
+	mux.HandleFunc("GET /computeMetadata/v1/project/project-id", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("my-project"))
+	})
+	mux.HandleFunc("GET /computeMetadata/v1/instance/zone", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("projects/1234/zones/europe-west1-b"))
+	})
+	mux.HandleFunc("GET /metadata/instance/compute", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"location":"westeurope","zone":"2","subscriptionId":"sub-1"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCloudDetector(t *testing.T) {
+	server := metadataServer(t)
+
+	tests := []struct {
+		provider string
+		want     []attribute.KeyValue
+	}{
+		{
+			provider: CloudProviderAWS,
+			want: []attribute.KeyValue{
+				semconv.CloudProviderAWS,
+				semconv.CloudPlatformAWSEC2,
+				semconv.CloudRegion("eu-west-1"),
+				semconv.CloudAvailabilityZone("eu-west-1a"),
+				semconv.CloudAccountID("123456789012"),
+			},
+		},
+		{
+			provider: CloudProviderGCP,
+			want: []attribute.KeyValue{
+				semconv.CloudProviderGCP,
+				semconv.CloudPlatformGCPComputeEngine,
+				semconv.CloudRegion("europe-west1"),
+				semconv.CloudAvailabilityZone("europe-west1-b"),
+				semconv.CloudAccountID("my-project"),
+			},
+		},
+		{
+			provider: CloudProviderAzure,
+			want: []attribute.KeyValue{
+				semconv.CloudProviderAzure,
+				semconv.CloudPlatformAzureVM,
+				semconv.CloudRegion("westeurope"),
+				semconv.CloudAccountID("sub-1"),
+				semconv.CloudAvailabilityZone("2"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			detector, err := newCloudDetector(ResourceConfig{CloudProvider: tt.provider, CloudMetadataEndpoint: server.URL + "/"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := detector.Detect(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			set := res.Set()
+			for _, want := range tt.want {
+				if got, ok := set.Value(want.Key); !ok || got != want.Value {
+					t.Errorf("%s = %v, want %v", want.Key, got.Emit(), want.Value.Emit())
+				}
+			}
+		})
+	}
+}
+
+func TestCloudDetectorUnreachable(t *testing.T) {
+	server := metadataServer(t)
+	server.Close()
+
+	detector, err := newCloudDetector(ResourceConfig{CloudProvider: CloudProviderAWS, CloudMetadataEndpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v, want an unreachable endpoint ignored", err)
+	}
+	if res.Len() != 0 {
+		t.Errorf("Detect() = %v, want no cloud attributes", res)
+	}
+}
+
+func TestMetadataClientIgnoresProxy(t *testing.T) {
+	transport, ok := metadataClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("metadataClient transport is %T, want *http.Transport", metadataClient.Transport)
+	}
+	if transport.Proxy != nil {
+		t.Error("metadataClient uses a proxy, want metadata requests sent directly")
+	}
+}
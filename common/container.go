@@ -0,0 +1,91 @@
+package common
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	cgroupPath    = "/proc/self/cgroup"
+	mountinfoPath = "/proc/self/mountinfo"
+)
+
+var (
+	containerIDPattern          = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	mountinfoContainerIDPattern = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+)
+
+// containerDetector detects container.id from cgroup v1 and v2 layouts, including the
+// scope names used by containerd, CRI-O and Docker under systemd.
+type containerDetector struct{}
+
+// Detect implements resource.Detector.
+func (containerDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	id := ""
+
+	if content, err := os.ReadFile(cgroupPath); err == nil {
+		id = containerIDFromCgroup(string(content))
+	}
+
+	// With cgroup v2 /proc/self/cgroup usually only holds "0::/", the id is then found in the mounts.
+	if id == "" {
+		if content, err := os.ReadFile(mountinfoPath); err == nil {
+			id = containerIDFromMountinfo(string(content))
+		}
+	}
+
+	if id == "" {
+		return resource.Empty(), nil
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, semconv.ContainerID(id)), nil
+}
+
+// containerIDFromCgroup extracts the container id from the contents of /proc/self/cgroup.
+func containerIDFromCgroup(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		// Every line has the "hierarchy-ID:controller-list:cgroup-path" format.
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		segments := strings.Split(parts[2], "/")
+		last := segments[len(segments)-1]
+
+		// containerd with the systemd cgroup driver uses "<slice>:cri-containerd:<id>".
+		if i := strings.LastIndex(last, ":"); i >= 0 {
+			last = last[i+1:]
+		}
+
+		last = strings.TrimSuffix(last, ".scope")
+		for _, prefix := range []string{"docker-", "cri-containerd-", "crio-", "libpod-"} {
+			last = strings.TrimPrefix(last, prefix)
+		}
+
+		if containerIDPattern.MatchString(last) {
+			return last
+		}
+	}
+
+	return ""
+}
+
+// containerIDFromMountinfo extracts the container id from the contents of /proc/self/mountinfo.
+// Only Docker style containers/<id> mounts are used: under containerd CRI the mounts live in
+// sandboxes/<id>, the id of the pod sandbox shared by every container of the pod, which would
+// report the same wrong container.id for all of them.
+func containerIDFromMountinfo(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if match := mountinfoContainerIDPattern.FindStringSubmatch(line); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,108 @@
+package common
+
+import "testing"
+
+const (
+	testContainerID = "2f4e8a3c9b1d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+	testSandboxID   = "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f2f4e8a3c9b1d7e6f5a4b3c2d1e0f"
+)
+
+func TestContainerIDFromCgroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "cgroup v1 docker",
+			content: "12:memory:/docker/" + testContainerID + "\n11:cpu,cpuacct:/docker/" + testContainerID + "\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "cgroup v1 kubepods",
+			content: "4:pids:/kubepods/besteffort/pod5c1f/" + testContainerID + "\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "cgroup v2 docker systemd scope",
+			content: "0::/system.slice/docker-" + testContainerID + ".scope\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "cgroup v2 containerd scope",
+			content: "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod5c1f.slice/cri-containerd-" + testContainerID + ".scope\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "containerd systemd driver",
+			content: "0::/kubepods-burstable-pod5c1f.slice:cri-containerd:" + testContainerID + "\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "cri-o scope",
+			content: "0::/kubepods.slice/crio-" + testContainerID + ".scope\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "podman libpod scope",
+			content: "0::/user.slice/libpod-" + testContainerID + ".scope\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "cgroup v2 namespaced root",
+			content: "0::/\n",
+		},
+		{
+			name:    "host process",
+			content: "0::/user.slice/user-1000.slice/session-2.scope\n",
+		},
+		{
+			name:    "short hex id",
+			content: "0::/docker/2f4e8a3c9b1d\n",
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerIDFromCgroup(tt.content); got != tt.want {
+				t.Errorf("containerIDFromCgroup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerIDFromMountinfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "docker hostname mount",
+			content: "1 0 0:1 / / rw - overlay overlay rw\n735 721 254:1 /var/lib/docker/containers/" + testContainerID + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n",
+			want:    testContainerID,
+		},
+		{
+			name:    "containerd sandbox mount is not the container",
+			content: "735 721 254:1 /var/lib/containerd/io.containerd.grpc.v1.cri/sandboxes/" + testSandboxID + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n",
+		},
+		{
+			name:    "containers storage without id",
+			content: "735 721 254:1 /var/lib/containers/storage/overlay /var/lib/containers/storage/overlay rw - ext4 /dev/vda1 rw\n",
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerIDFromMountinfo(tt.content); got != tt.want {
+				t.Errorf("containerIDFromMountinfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDebugNameLength is the number of characters of a name or log body kept in export debug logs.
+const maxDebugNameLength = 128
+
+// LogExportBatch logs a summary of an export batch at debug level: the signal name ("traces",
+// "metrics" or "logs"), the number of items, the name of the first item, truncated so large log
+// bodies do not flood the output, the export duration and error, if any.
+func LogExportBatch(ctx context.Context, logger *slog.Logger, signal string, count int, first string, duration time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("signal", signal),
+		slog.Int("count", count),
+		slog.String("first", truncate(first, maxDebugNameLength)),
+		slog.Duration("duration", duration),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	logger.LogAttrs(ctx, slog.LevelDebug, "otelgo export batch", attrs...)
+}
+
+// truncate returns s cut to at most n characters, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n]) + "…"
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogExportBatch(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	LogExportBatch(context.Background(), logger, "logs", 3, strings.Repeat("x", 200), time.Millisecond, errors.New("collector unavailable"))
+
+	got := buf.String()
+	for _, want := range []string{
+		"level=DEBUG",
+		`msg="otelgo export batch"`,
+		"signal=logs",
+		"count=3",
+		"first=" + strings.Repeat("x", maxDebugNameLength) + "…",
+		`error="collector unavailable"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output %q does not contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, strings.Repeat("x", maxDebugNameLength+1)) {
+		t.Errorf("log output %q was not truncated to %d characters", got, maxDebugNameLength)
+	}
+}
+
+func TestLogExportBatchAboveDebug(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	LogExportBatch(context.Background(), logger, "traces", 1, "operation", time.Millisecond, nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("log output = %q, want nothing above debug level", buf.String())
+	}
+}
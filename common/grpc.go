@@ -0,0 +1,168 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// defaultGrpcEndpoint is the OTLP gRPC endpoint used when none is configured.
+const defaultGrpcEndpoint = "localhost:4317"
+
+// GrpcEndpoint returns the OTLP gRPC endpoint from the given signal specific environment variable,
+// e.g. OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, falling back to OTEL_EXPORTER_OTLP_ENDPOINT and
+// localhost:4317. The URL scheme, if any, is removed.
+func GrpcEndpoint(signalEnv string) string {
+	endpoint := os.Getenv(signalEnv)
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		return defaultGrpcEndpoint
+	}
+
+	return GrpcTarget(endpoint)
+}
+
+// GrpcTarget returns endpoint as a gRPC dial target, removing the URL scheme, if any, and the
+// trailing slash.
+func GrpcTarget(endpoint string) string {
+	if _, rest, ok := strings.Cut(endpoint, "://"); ok {
+		endpoint = rest
+	}
+
+	return strings.TrimSuffix(endpoint, "/")
+}
+
+// WatchConnState reports the current state of conn and every later state transition to callback
+// until ctx is done.
+func WatchConnState(ctx context.Context, conn *grpc.ClientConn, callback func(connectivity.State)) {
+	state := conn.GetState()
+	callback(state)
+
+	for conn.WaitForStateChange(ctx, state) {
+		state = conn.GetState()
+		callback(state)
+	}
+}
+
+// WaitForReady blocks until conn is ready or ctx is done, in which case the last connection
+// state is reported along with the context error. Transient failures are retried by gRPC.
+func WaitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection %s: %w", strings.ToLower(state.String()), ctx.Err())
+		}
+	}
+}
+
+// sharedConnKey identifies a shared connection by its target and the settings it was dialed with.
+type sharedConnKey struct {
+	target  string
+	options string
+}
+
+// sharedConn is a connection used by several exporters.
+type sharedConn struct {
+	conn *grpc.ClientConn
+	refs int
+}
+
+// otlpEnv returns the OTEL_EXPORTER_OTLP_<signal>_<name> variable, e.g.
+// OTEL_EXPORTER_OTLP_METRICS_CERTIFICATE, falling back to OTEL_EXPORTER_OTLP_<name>.
+func otlpEnv(signal, name string) string {
+	if value := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_" + name); value != "" {
+		return value
+	}
+
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+}
+
+// SharedGrpcCredentials returns the transport credentials of a shared connection of the given
+// signal ("TRACES", "METRICS" or "LOGS") and the options key identifying them for AcquireGrpcConn.
+// Explicit settings are used as given. Otherwise the OTLP TLS variables are honoured like the
+// exporters do, the signal specific one first: *INSECURE=true dials without TLS, *CERTIFICATE,
+// *CLIENT_CERTIFICATE and *CLIENT_KEY set the CA and the client certificate. Without any of them the
+// otelgo default of NewTLSConfig(nil) is used, so the signals share a connection unless configured
+// differently.
+func SharedGrpcCredentials(settings *TLSConfig, signal string) (credentials.TransportCredentials, string, error) {
+	if settings == nil {
+		if plaintext, _ := strconv.ParseBool(otlpEnv(signal, "INSECURE")); plaintext {
+			return insecure.NewCredentials(), "plaintext", nil
+		}
+
+		ca, cert, key := otlpEnv(signal, "CERTIFICATE"), otlpEnv(signal, "CLIENT_CERTIFICATE"), otlpEnv(signal, "CLIENT_KEY")
+		if ca != "" || cert != "" || key != "" {
+			settings = &TLSConfig{CACertPath: ca, ClientCertPath: cert, ClientKeyPath: key}
+		}
+	}
+
+	tlsConfig, err := NewTLSConfig(settings)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return credentials.NewTLS(tlsConfig), TLSConfigKey(settings), nil
+}
+
+var (
+	sharedConnsMu sync.Mutex
+	sharedConns   = map[sharedConnKey]*sharedConn{}
+)
+
+// AcquireGrpcConn returns a connection to target shared by every caller passing the same target
+// and options key, dialing it with opts on first use. The options key must identify the settings
+// expressed by opts, such as TLSConfigKey for the transport credentials, since later callers get
+// the existing connection and their opts are ignored.
+//
+// The returned release function must be called once the connection is no longer used. The
+// connection is closed when the last user releases it, further calls to release do nothing.
+func AcquireGrpcConn(target, options string, opts ...grpc.DialOption) (*grpc.ClientConn, func() error, error) {
+	key := sharedConnKey{target: target, options: options}
+
+	sharedConnsMu.Lock()
+	defer sharedConnsMu.Unlock()
+
+	shared, ok := sharedConns[key]
+	if !ok {
+		conn, err := grpc.NewClient(target, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		shared = &sharedConn{conn: conn}
+		sharedConns[key] = shared
+	}
+	shared.refs++
+
+	var once sync.Once
+	release := func() error {
+		var err error
+		once.Do(func() {
+			sharedConnsMu.Lock()
+			defer sharedConnsMu.Unlock()
+
+			shared.refs--
+			if shared.refs == 0 {
+				delete(sharedConns, key)
+				err = shared.conn.Close()
+			}
+		})
+		return err
+	}
+
+	return shared.conn, release, nil
+}
@@ -0,0 +1,185 @@
+package common
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// writeTestCA writes a self-signed CA certificate to name in dir and returns its path.
+func writeTestCA(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestSharedGrpcCredentials(t *testing.T) {
+	dir := t.TempDir()
+	ca, metricsCA := writeTestCA(t, dir, "ca.pem"), writeTestCA(t, dir, "metrics-ca.pem")
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		settings *TLSConfig
+		signal   string
+		wantKey  string
+	}{
+		{
+			name:    "default",
+			signal:  "METRICS",
+			wantKey: TLSConfigKey(nil),
+		},
+		{
+			name:    "signal insecure",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_METRICS_INSECURE": "true"},
+			signal:  "METRICS",
+			wantKey: "plaintext",
+		},
+		{
+			name:    "generic insecure",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_INSECURE": "true"},
+			signal:  "LOGS",
+			wantKey: "plaintext",
+		},
+		{
+			name:    "other signal insecure",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_TRACES_INSECURE": "true"},
+			signal:  "LOGS",
+			wantKey: TLSConfigKey(nil),
+		},
+		{
+			name:    "certificate",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_CERTIFICATE": ca, "OTEL_EXPORTER_OTLP_METRICS_CERTIFICATE": metricsCA},
+			signal:  "METRICS",
+			wantKey: TLSConfigKey(&TLSConfig{CACertPath: metricsCA}),
+		},
+		{
+			name:     "explicit settings win",
+			env:      map[string]string{"OTEL_EXPORTER_OTLP_INSECURE": "true"},
+			settings: &TLSConfig{Insecure: true},
+			signal:   "TRACES",
+			wantKey:  TLSConfigKey(&TLSConfig{Insecure: true}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"INSECURE", "CERTIFICATE", "CLIENT_CERTIFICATE", "CLIENT_KEY"} {
+				t.Setenv("OTEL_EXPORTER_OTLP_"+name, "")
+				t.Setenv("OTEL_EXPORTER_OTLP_"+tt.signal+"_"+name, "")
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			creds, key, err := SharedGrpcCredentials(tt.settings, tt.signal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds == nil {
+				t.Fatal("SharedGrpcCredentials returned nil credentials")
+			}
+			if key != tt.wantKey {
+				t.Errorf("key = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestSharedGrpcCredentialsMatchAcrossSignals(t *testing.T) {
+	keys := map[string]bool{}
+	for _, signal := range []string{"TRACES", "METRICS", "LOGS"} {
+		_, key, err := SharedGrpcCredentials(nil, signal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[key] = true
+	}
+
+	if len(keys) != 1 {
+		t.Errorf("signals use different keys %v, want one shared key", keys)
+	}
+}
+
+func TestWatchConnState(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	states := make(chan connectivity.State, 10)
+	done := make(chan struct{})
+	go func() {
+		WatchConnState(ctx, conn, func(state connectivity.State) { states <- state })
+		close(done)
+	}()
+	conn.Connect()
+
+	reported := []connectivity.State{}
+	timeout := time.After(5 * time.Second)
+	for len(reported) == 0 || reported[len(reported)-1] != connectivity.Ready {
+		select {
+		case state := <-states:
+			reported = append(reported, state)
+		case <-timeout:
+			t.Fatalf("reported states %v, want a transition to READY", reported)
+		}
+	}
+	if len(reported) < 2 {
+		t.Errorf("reported states %v, want the initial state and at least one transition", reported)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("WatchConnState did not return after its context was canceled")
+	}
+}
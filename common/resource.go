@@ -0,0 +1,318 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ResourceConfig specifies how the resource shared by logs, metrics and tracing is detected.
+type ResourceConfig struct {
+	ExcludeOSDescription      bool              `json:"exclude_os_description"`       // ExcludeOSDescription specifies whether os.description (kernel details) is left out, keeping only os.type. Default is false.
+	ContainerV2Detection      bool              `json:"container_v2_detection"`       // ContainerV2Detection specifies whether container.id is also detected from cgroup v2 and containerd scope names. Default is false.
+	RedactionPatterns         []string          `json:"redaction_patterns"`           // RedactionPatterns specifies regular expressions whose matches are masked in resource attribute values, e.g. tokens in process.command_args. Default is nil.
+	DetectorFuncs             []DetectorFunc    `json:"-"`                            // DetectorFuncs specifies functions run as additional resource detectors. Default is nil.
+	ServiceNameFromExecutable bool              `json:"service_name_from_executable"` // ServiceNameFromExecutable specifies whether service.name defaults to the executable base name when it is not configured. Default is false.
+	DisabledDetectors         ResourceDetectors `json:"disabled_detectors"`           // DisabledDetectors specifies the standard detectors to skip. Default is none, all detectors run.
+	HostIDPath                string            `json:"host_id_path"`                 // HostIDPath specifies a file, e.g. /etc/machine-id, whose content overrides the detected host.id. Default is empty, keeping the detected value.
+	ExcludedProcessAttributes ProcessAttributes `json:"excluded_process_attributes"`  // ExcludedProcessAttributes specifies the process attributes left out of the resource, e.g. process.owner. Default is none.
+	InstanceIDFromHostname    bool              `json:"instance_id_from_hostname"`    // InstanceIDFromHostname specifies whether service.instance.id defaults to the hostname, e.g. the pod name of a StatefulSet, when it is not configured. Default is false.
+	ResourceProvider          ResourceProvider  `json:"-"`                            // ResourceProvider specifies a function fetching attributes once at Init, e.g. from platform annotations, bounded by ResourceProviderTimeout. Default is nil.
+	ResourceProviderTimeout   time.Duration     `json:"resource_provider_timeout"`    // ResourceProviderTimeout specifies how long Init waits for ResourceProvider before proceeding without its attributes. Default is 5 seconds.
+	CloudProvider             string            `json:"cloud_provider"`               // CloudProvider specifies the cloud, "aws", "gcp" or "azure", whose instance metadata service provides the cloud.* attributes. Default is empty, skipping cloud detection.
+	CloudMetadataEndpoint     string            `json:"cloud_metadata_endpoint"`      // CloudMetadataEndpoint specifies the base URL of the instance metadata service. Default is http://169.254.169.254.
+	CloudDetectionTimeout     time.Duration     `json:"cloud_detection_timeout"`      // CloudDetectionTimeout specifies how long cloud detection may take before it is skipped. Default is 2 seconds.
+}
+
+// ResourceDetectors selects standard resource detectors. Each field set to true refers to the
+// corresponding detector.
+type ResourceDetectors struct {
+	Host         bool `json:"host"`          // Host refers to resource.WithHost.
+	Container    bool `json:"container"`     // Container refers to resource.WithContainer.
+	Process      bool `json:"process"`       // Process refers to resource.WithProcess, which includes process.command_args.
+	OS           bool `json:"os"`            // OS refers to resource.WithOS.
+	TelemetrySDK bool `json:"telemetry_sdk"` // TelemetrySDK refers to resource.WithTelemetrySDK.
+	FromEnv      bool `json:"from_env"`      // FromEnv refers to resource.WithFromEnv (OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME). The SDK providers merge these variables into their resource regardless, only exported spans leave them out.
+}
+
+// ProcessAttributes selects attributes of the process detector. Each field set to true refers to
+// the corresponding attributes.
+type ProcessAttributes struct {
+	PID            bool `json:"pid"`             // PID refers to process.pid.
+	ExecutableName bool `json:"executable_name"` // ExecutableName refers to process.executable.name.
+	ExecutablePath bool `json:"executable_path"` // ExecutablePath refers to process.executable.path.
+	CommandArgs    bool `json:"command_args"`    // CommandArgs refers to process.command_args.
+	Owner          bool `json:"owner"`           // Owner refers to process.owner.
+	Runtime        bool `json:"runtime"`         // Runtime refers to process.runtime.name, version and description.
+}
+
+// processOptions returns the process detector options without the excluded attributes.
+func processOptions(excluded ProcessAttributes) []resource.Option {
+	opts := []resource.Option{}
+
+	if !excluded.PID {
+		opts = append(opts, resource.WithProcessPID())
+	}
+	if !excluded.ExecutableName {
+		opts = append(opts, resource.WithProcessExecutableName())
+	}
+	if !excluded.ExecutablePath {
+		opts = append(opts, resource.WithProcessExecutablePath())
+	}
+	if !excluded.CommandArgs {
+		opts = append(opts, resource.WithProcessCommandArgs())
+	}
+	if !excluded.Owner {
+		opts = append(opts, resource.WithProcessOwner())
+	}
+	if !excluded.Runtime {
+		opts = append(opts,
+			resource.WithProcessRuntimeName(),
+			resource.WithProcessRuntimeVersion(),
+			resource.WithProcessRuntimeDescription(),
+		)
+	}
+
+	return opts
+}
+
+// DetectorFunc returns attributes to be added to the resource. It is a lightweight alternative
+// to implementing resource.Detector.
+type DetectorFunc func(ctx context.Context) ([]attribute.KeyValue, error)
+
+// Detect implements resource.Detector. A failing function does not fail resource creation: the
+// error is reported through the global OpenTelemetry error handler and its attributes are skipped.
+func (f DetectorFunc) Detect(ctx context.Context) (*resource.Resource, error) {
+	attrs, err := f(ctx)
+	if err != nil {
+		otel.Handle(fmt.Errorf("resource detector func: %w", err))
+		return resource.Empty(), nil
+	}
+
+	return resource.NewSchemaless(attrs...), nil
+}
+
+// ResourceProvider returns attributes to be added to the resource, typically fetched from a remote
+// source. Unlike a DetectorFunc it is bounded by a timeout, so a slow source cannot hold up Init.
+type ResourceProvider func(ctx context.Context) ([]attribute.KeyValue, error)
+
+// defaultResourceProviderTimeout is how long Init waits for a ResourceProvider by default.
+const defaultResourceProviderTimeout = 5 * time.Second
+
+// providerDetector runs a ResourceProvider in the background and waits for it up to timeout.
+type providerDetector struct {
+	provider ResourceProvider
+	timeout  time.Duration
+}
+
+// Detect implements resource.Detector. A failing or timed out provider does not fail resource
+// creation: the error is reported through the global OpenTelemetry error handler and its
+// attributes are skipped.
+func (d providerDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	timeout := d.timeout
+	if timeout <= 0 {
+		timeout = defaultResourceProviderTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		attrs []attribute.KeyValue
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		attrs, err := d.provider(ctx)
+		done <- result{attrs: attrs, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			otel.Handle(fmt.Errorf("resource provider: %w", r.err))
+			return resource.Empty(), nil
+		}
+		return resource.NewSchemaless(r.attrs...), nil
+	case <-ctx.Done():
+		otel.Handle(fmt.Errorf("resource provider: proceeding without its attributes: %w", ctx.Err()))
+		return resource.Empty(), nil
+	}
+}
+
+// hostIDDetector sets host.id from the content of a file such as /etc/machine-id.
+type hostIDDetector struct {
+	path string
+}
+
+// Detect implements resource.Detector.
+func (d hostIDDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	content, err := os.ReadFile(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading host id: %w", err)
+	}
+
+	id := strings.TrimSpace(string(content))
+	if id == "" {
+		return nil, fmt.Errorf("reading host id: %s is empty", d.path)
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, semconv.HostID(id)), nil
+}
+
+// NewResource builds a resource from the standard detectors and the given attributes.
+func NewResource(ctx context.Context, config ResourceConfig, attrs []attribute.KeyValue) (*resource.Resource, error) {
+	disabled := config.DisabledDetectors
+	opts := []resource.Option{}
+
+	if !disabled.Host {
+		opts = append(opts, resource.WithHost())
+	}
+	// The stable host id detector runs after resource.WithHost() so its host.id takes precedence.
+	if config.HostIDPath != "" {
+		opts = append(opts, resource.WithDetectors(hostIDDetector{path: config.HostIDPath}))
+	}
+	if !disabled.Container {
+		opts = append(opts, resource.WithContainer())
+	}
+	if !disabled.Process {
+		opts = append(opts, processOptions(config.ExcludedProcessAttributes)...)
+	}
+	if !disabled.TelemetrySDK {
+		opts = append(opts, resource.WithTelemetrySDK())
+	}
+
+	// The additional detector runs after resource.WithContainer() so its container.id takes precedence.
+	if config.ContainerV2Detection {
+		opts = append(opts, resource.WithDetectors(containerDetector{}))
+	}
+
+	if config.CloudProvider != "" {
+		detector, err := newCloudDetector(config)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, resource.WithDetectors(detector))
+	}
+
+	for _, fn := range config.DetectorFuncs {
+		opts = append(opts, resource.WithDetectors(fn))
+	}
+
+	if config.ResourceProvider != nil {
+		opts = append(opts, resource.WithDetectors(providerDetector{provider: config.ResourceProvider, timeout: config.ResourceProviderTimeout}))
+	}
+
+	if !disabled.OS {
+		if config.ExcludeOSDescription {
+			opts = append(opts, resource.WithOSType())
+		} else {
+			opts = append(opts, resource.WithOS())
+		}
+	}
+
+	if !disabled.FromEnv {
+		opts = append(opts, resource.WithFromEnv())
+	}
+
+	opts = append(opts, resource.WithAttributes(attrs...))
+
+	res, err := resource.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ServiceNameFromExecutable {
+		res, err = withExecutableServiceName(res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.InstanceIDFromHostname {
+		res, err = withHostnameInstanceID(res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.RedactionPatterns) > 0 {
+		return redactResource(res, config.RedactionPatterns)
+	}
+
+	return res, nil
+}
+
+// withExecutableServiceName sets service.name to the executable base name when the resource has
+// no service name, an empty one, or the SDK "unknown_service" fallback.
+func withExecutableServiceName(res *resource.Resource) (*resource.Resource, error) {
+	name, ok := res.Set().Value(semconv.ServiceNameKey)
+	if ok && name.AsString() != "" && !strings.HasPrefix(name.AsString(), "unknown_service") {
+		return res, nil
+	}
+
+	return resource.Merge(res, resource.NewSchemaless(semconv.ServiceName(filepath.Base(os.Args[0]))))
+}
+
+// withHostnameInstanceID sets service.instance.id to the hostname when the resource has no
+// service instance id or an empty one.
+func withHostnameInstanceID(res *resource.Resource) (*resource.Resource, error) {
+	if id, ok := res.Set().Value(semconv.ServiceInstanceIDKey); ok && id.AsString() != "" {
+		return res, nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("reading hostname for service.instance.id: %w", err)
+	}
+
+	return resource.Merge(res, resource.NewSchemaless(semconv.ServiceInstanceID(hostname)))
+}
+
+// redactedValue replaces every substring matched by a redaction pattern.
+const redactedValue = "***"
+
+// redactResource returns a copy of res with every match of the given patterns masked in
+// string and string slice attribute values.
+func redactResource(res *resource.Resource, patterns []string) (*resource.Resource, error) {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		regexps = append(regexps, re)
+	}
+
+	redact := func(value string) string {
+		for _, re := range regexps {
+			value = re.ReplaceAllString(value, redactedValue)
+		}
+		return value
+	}
+
+	attrs := res.Attributes()
+	for i, attr := range attrs {
+		switch attr.Value.Type() {
+		case attribute.STRING:
+			attrs[i] = attr.Key.String(redact(attr.Value.AsString()))
+		case attribute.STRINGSLICE:
+			values := attr.Value.AsStringSlice()
+			for j := range values {
+				values[j] = redact(values[j])
+			}
+			attrs[i] = attr.Key.StringSlice(values)
+		}
+	}
+
+	return resource.NewWithAttributes(res.SchemaURL(), attrs...), nil
+}
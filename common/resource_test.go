@@ -0,0 +1,267 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// newTestResource returns the resource created by NewResource for config and attrs.
+func newTestResource(t *testing.T, config ResourceConfig, attrs ...attribute.KeyValue) *resource.Resource {
+	t.Helper()
+
+	res, err := NewResource(context.Background(), config, attrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+func TestNewResourceOSDescription(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          ResourceConfig
+		wantDescription bool
+	}{
+		{name: "default", wantDescription: true},
+		{name: "excluded", config: ResourceConfig{ExcludeOSDescription: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newTestResource(t, tt.config).Set()
+
+			if !set.HasValue("os.type") {
+				t.Error("os.type is missing")
+			}
+			if got := set.HasValue("os.description"); got != tt.wantDescription {
+				t.Errorf("os.description present = %t, want %t", got, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestNewResourceRedaction(t *testing.T) {
+	args := os.Args
+	os.Args = []string{args[0], "--token=ghp_abc123secret", "--verbose"}
+	defer func() { os.Args = args }()
+
+	config := ResourceConfig{RedactionPatterns: []string{`ghp_[A-Za-z0-9]+`, `secret-[0-9]+`}}
+	set := newTestResource(t, config, attribute.String("deployment.note", "key secret-42 rotated")).Set()
+
+	commandArgs, _ := set.Value("process.command_args")
+	want := []string{args[0], "--token=***", "--verbose"}
+	if got := commandArgs.AsStringSlice(); !reflect.DeepEqual(got, want) {
+		t.Errorf("process.command_args = %q, want %q", got, want)
+	}
+
+	if got, _ := set.Value("deployment.note"); got.AsString() != "key *** rotated" {
+		t.Errorf("deployment.note = %q, want the secret masked", got.AsString())
+	}
+}
+
+func TestNewResourceInvalidRedactionPattern(t *testing.T) {
+	_, err := NewResource(context.Background(), ResourceConfig{RedactionPatterns: []string{"("}}, nil)
+	if err == nil {
+		t.Error("NewResource succeeded with an invalid pattern, want an error")
+	}
+}
+
+// errorRecorder keeps the errors reported to the global OpenTelemetry error handler.
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) Handle(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err)
+}
+
+// recordErrors sends the errors reported to the global error handler to the returned recorder
+// until the test ends.
+func recordErrors(t *testing.T) *errorRecorder {
+	t.Helper()
+
+	recorder := &errorRecorder{}
+	handler := otel.GetErrorHandler()
+	otel.SetErrorHandler(recorder)
+	t.Cleanup(func() { otel.SetErrorHandler(handler) })
+
+	return recorder
+}
+
+func TestNewResourceDetectorFuncs(t *testing.T) {
+	errs := recordErrors(t)
+
+	config := ResourceConfig{DetectorFuncs: []DetectorFunc{
+		func(context.Context) ([]attribute.KeyValue, error) {
+			return []attribute.KeyValue{attribute.String("team", "payments"), attribute.Int("shard", 3)}, nil
+		},
+		func(context.Context) ([]attribute.KeyValue, error) {
+			return []attribute.KeyValue{attribute.String("failed", "value")}, errors.New("metadata unavailable")
+		},
+	}}
+	set := newTestResource(t, config).Set()
+
+	if got, _ := set.Value("team"); got.AsString() != "payments" {
+		t.Errorf("team = %q, want payments", got.AsString())
+	}
+	if got, _ := set.Value("shard"); got.AsInt64() != 3 {
+		t.Errorf("shard = %d, want 3", got.AsInt64())
+	}
+	if set.HasValue("failed") {
+		t.Error("attributes of the failing detector func were added")
+	}
+	if len(errs.errs) != 1 {
+		t.Errorf("reported errors = %v, want the detector func error", errs.errs)
+	}
+}
+
+func TestNewResourceServiceNameFromExecutable(t *testing.T) {
+	executable := filepath.Base(os.Args[0])
+
+	tests := []struct {
+		name        string
+		serviceName string
+		config      ResourceConfig
+		want        string
+	}{
+		{name: "disabled", want: ""},
+		{name: "enabled", config: ResourceConfig{ServiceNameFromExecutable: true}, want: executable},
+		{name: "configured name wins", serviceName: "orders", config: ResourceConfig{ServiceNameFromExecutable: true}, want: "orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_SERVICE_NAME", tt.serviceName)
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+			got, _ := newTestResource(t, tt.config).Set().Value("service.name")
+			if got.AsString() != tt.want {
+				t.Errorf("service.name = %q, want %q", got.AsString(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResourceHostIDPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machine-id")
+	if err := os.WriteFile(path, []byte("4f9c2d7e1a8b4c3d9e6f0a1b2c3d4e5f\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	set := newTestResource(t, ResourceConfig{HostIDPath: path}).Set()
+	if got, _ := set.Value("host.id"); got.AsString() != "4f9c2d7e1a8b4c3d9e6f0a1b2c3d4e5f" {
+		t.Errorf("host.id = %q, want the machine-id file content", got.AsString())
+	}
+}
+
+func TestNewResourceExcludedProcessAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		excluded  ProcessAttributes
+		wantOwner bool
+	}{
+		{name: "default", wantOwner: true},
+		{name: "owner excluded", excluded: ProcessAttributes{Owner: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newTestResource(t, ResourceConfig{ExcludedProcessAttributes: tt.excluded}).Set()
+
+			if got := set.HasValue("process.owner"); got != tt.wantOwner {
+				t.Errorf("process.owner present = %t, want %t", got, tt.wantOwner)
+			}
+			if !set.HasValue("process.pid") {
+				t.Error("process.pid is missing")
+			}
+		})
+	}
+}
+
+func TestNewResourceInstanceIDFromHostname(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		resourceEnv string
+		config      ResourceConfig
+		want        string
+	}{
+		{name: "disabled"},
+		{name: "hostname", config: ResourceConfig{InstanceIDFromHostname: true}, want: hostname},
+		{name: "configured id wins", resourceEnv: "service.instance.id=orders-7", config: ResourceConfig{InstanceIDFromHostname: true}, want: "orders-7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", tt.resourceEnv)
+
+			got, _ := newTestResource(t, tt.config).Set().Value("service.instance.id")
+			if got.AsString() != tt.want {
+				t.Errorf("service.instance.id = %q, want %q", got.AsString(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResourceProvider(t *testing.T) {
+	errs := recordErrors(t)
+
+	config := ResourceConfig{ResourceProvider: func(context.Context) ([]attribute.KeyValue, error) {
+		return []attribute.KeyValue{attribute.String("platform.team", "payments")}, nil
+	}}
+	set := newTestResource(t, config).Set()
+
+	if got, _ := set.Value("platform.team"); got.AsString() != "payments" {
+		t.Errorf("platform.team = %q, want payments", got.AsString())
+	}
+	if len(errs.errs) != 0 {
+		t.Errorf("reported errors = %v, want none", errs.errs)
+	}
+}
+
+func TestNewResourceProviderTimeout(t *testing.T) {
+	errs := recordErrors(t)
+
+	config := ResourceConfig{
+		ResourceProvider: func(ctx context.Context) ([]attribute.KeyValue, error) {
+			<-ctx.Done()
+			return []attribute.KeyValue{attribute.String("platform.team", "payments")}, nil
+		},
+		ResourceProviderTimeout: 50 * time.Millisecond,
+	}
+
+	start := time.Now()
+	set := newTestResource(t, config).Set()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("NewResource took %s, want it to proceed after the 50ms timeout", elapsed)
+	}
+
+	if set.HasValue("platform.team") {
+		t.Error("attributes of the timed out provider were added")
+	}
+	if !set.HasValue("telemetry.sdk.name") {
+		t.Error("telemetry.sdk.name is missing, want the resource detected without the provider")
+	}
+	if len(errs.errs) != 1 || !errors.Is(errs.errs[0], context.DeadlineExceeded) {
+		t.Errorf("reported errors = %v, want a timeout warning", errs.errs)
+	}
+}
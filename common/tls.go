@@ -0,0 +1,85 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSConfig specifies the TLS settings used by the OTLP exporters.
+type TLSConfig struct {
+	Insecure       bool   `json:"insecure"`         // Insecure specifies whether server certificate verification is skipped. Default is false.
+	CACertPath     string `json:"ca_cert_path"`     // CACertPath specifies a PEM file with the CA certificates used to verify the server. Default is the system pool.
+	ClientCertPath string `json:"client_cert_path"` // ClientCertPath specifies a PEM certificate the exporter presents to the collector (mTLS), not the server certificate. Default is empty.
+	ClientKeyPath  string `json:"client_key_path"`  // ClientKeyPath specifies the PEM private key matching ClientCertPath. Default is empty.
+	ServerName     string `json:"server_name"`      // ServerName specifies the name used to verify the server certificate. Default is the endpoint host.
+}
+
+// Validate checks that the settings are consistent. The client certificate and key are presented
+// by the exporter to the collector for mutual TLS, which is pointless over a connection whose server
+// certificate is not verified, so they cannot be combined with Insecure.
+func (c *TLSConfig) Validate() error {
+	if (c.ClientCertPath == "") != (c.ClientKeyPath == "") {
+		return errors.New("tls: ClientCertPath and ClientKeyPath must be set together")
+	}
+
+	if c.ClientCertPath != "" && c.Insecure {
+		return errors.New("tls: a client certificate cannot be used with Insecure, server verification is required for mTLS")
+	}
+
+	return nil
+}
+
+// TLSConfigKey returns a string identifying the *tls.Config NewTLSConfig builds from config, so
+// that equal settings can share a connection.
+func TLSConfigKey(config *TLSConfig) string {
+	if config == nil {
+		return "insecure-skip-verify"
+	}
+
+	return fmt.Sprintf("%+v", *config)
+}
+
+// NewTLSConfig builds a *tls.Config from the given settings. A nil config keeps the
+// historical otelgo behaviour of skipping server certificate verification.
+func NewTLSConfig(config *TLSConfig) (*tls.Config, error) {
+	if config == nil {
+		return &tls.Config{
+			InsecureSkipVerify: true, // WARNING: Skips certificate verification
+		}, nil
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.Insecure,
+		ServerName:         config.ServerName,
+	}
+
+	if config.CACertPath != "" {
+		pem, err := os.ReadFile(config.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("tls: reading CA certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates found in %s", config.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCertPath, config.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("tls: loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
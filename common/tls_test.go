@@ -0,0 +1,34 @@
+package common
+
+import "testing"
+
+func TestTLSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TLSConfig
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "insecure", config: TLSConfig{Insecure: true}},
+		{name: "client certificate", config: TLSConfig{ClientCertPath: "client.pem", ClientKeyPath: "client-key.pem"}},
+		{name: "client certificate with insecure", config: TLSConfig{Insecure: true, ClientCertPath: "client.pem", ClientKeyPath: "client-key.pem"}, wantErr: true},
+		{name: "certificate without key", config: TLSConfig{ClientCertPath: "client.pem"}, wantErr: true},
+		{name: "key without certificate", config: TLSConfig{ClientKeyPath: "client-key.pem"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigRejectsClientCertificateWithInsecure(t *testing.T) {
+	_, err := NewTLSConfig(&TLSConfig{Insecure: true, ClientCertPath: "client.pem", ClientKeyPath: "client-key.pem"})
+	if err == nil {
+		t.Error("NewTLSConfig succeeded with a client certificate and Insecure, want an error")
+	}
+}
@@ -1,8 +1,20 @@
 package common
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 func IsOtlpProtocolGrpc(dataType string) bool {
@@ -13,3 +25,295 @@ func IsOtlpProtocolGrpc(dataType string) bool {
 
 	return os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL") == "grpc"
 }
+
+// ProtocolNone is the OTLP protocol that disables a signal, set in its specific variable, e.g.
+// OTEL_EXPORTER_OTLP_METRICS_PROTOCOL, or in OTEL_EXPORTER_OTLP_PROTOCOL for all signals. The
+// provider of a disabled signal is still returned by Init but never exports.
+const ProtocolNone = "none"
+
+// IsSignalDisabled reports whether a signal is turned off by the environment: OTEL_SDK_DISABLED,
+// its exporter variable, e.g. OTEL_METRICS_EXPORTER, set to "none" or its protocol variable, e.g.
+// OTEL_EXPORTER_OTLP_METRICS_PROTOCOL, set to ProtocolNone.
+func IsSignalDisabled(exporterEnv, protocolEnv string) bool {
+	return IsSdkDisabled() || os.Getenv(exporterEnv) == "none" || IsOtlpProtocolNone(protocolEnv)
+}
+
+// OTLP protocols supported in OTEL_EXPORTER_OTLP_PROTOCOL and the signal specific variables,
+// along with ProtocolNone.
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+	ProtocolHTTPJSON     = "http/json"
+)
+
+// OtlpProtocol returns the OTLP protocol from the given signal specific variable, falling back to
+// OTEL_EXPORTER_OTLP_PROTOCOL and http/protobuf. An unknown protocol is reported as an error.
+func OtlpProtocol(dataType string) (string, error) {
+	protocol := os.Getenv(strings.ToUpper(dataType))
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	switch protocol {
+	case "":
+		return ProtocolHTTPProtobuf, nil
+	case ProtocolGRPC, ProtocolHTTPProtobuf, ProtocolHTTPJSON, ProtocolNone:
+		return protocol, nil
+	default:
+		return "", fmt.Errorf("unsupported protocol %q, expected %s, %s, %s or %s", protocol, ProtocolGRPC, ProtocolHTTPProtobuf, ProtocolHTTPJSON, ProtocolNone)
+	}
+}
+
+// IsOtlpProtocolNone reports whether the signal is disabled by the given signal specific protocol
+// variable, falling back to OTEL_EXPORTER_OTLP_PROTOCOL when it is not set.
+func IsOtlpProtocolNone(dataType string) bool {
+	protocol := os.Getenv(strings.ToUpper(dataType))
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	return protocol == ProtocolNone
+}
+
+// AttributesFromMap converts a map of string values into a slice of attributes.
+// The result is sorted by key so the resource is built deterministically.
+func AttributesFromMap(m map[string]string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(m))
+	for k, v := range m {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+
+	return attrs
+}
+
+// MergeAttributes combines the given attribute slices into one, keeping the
+// last value for every duplicated key. The result is sorted by key.
+func MergeAttributes(attrs ...[]attribute.KeyValue) []attribute.KeyValue {
+	all := make([]attribute.KeyValue, 0)
+	for _, a := range attrs {
+		all = append(all, a...)
+	}
+
+	set := attribute.NewSet(all...)
+
+	return set.ToSlice()
+}
+
+// ServiceNameAttributes returns the service.name attribute from OTEL_SERVICE_NAME, read when
+// called, or no attributes at all when the variable is empty, leaving the name to
+// OTEL_RESOURCE_ATTRIBUTES and the SDK default.
+func ServiceNameAttributes() []attribute.KeyValue {
+	name := os.Getenv("OTEL_SERVICE_NAME")
+	if name == "" {
+		return nil
+	}
+
+	return []attribute.KeyValue{semconv.ServiceNameKey.String(name)}
+}
+
+// ServiceVersionAttributes returns the service.version attribute for the given
+// version, or no attributes at all when the version is empty.
+func ServiceVersionAttributes(version string) []attribute.KeyValue {
+	if version == "" {
+		return nil
+	}
+
+	return []attribute.KeyValue{semconv.ServiceVersionKey.String(version)}
+}
+
+// ServiceGroupKey is the resource attribute grouping services into a logical system.
+const ServiceGroupKey = attribute.Key("service.group")
+
+// ServiceGroupAttributes returns the service.group attribute for the given group, falling back
+// to OTEL_SERVICE_GROUP, or no attributes at all when neither is set.
+func ServiceGroupAttributes(group string) []attribute.KeyValue {
+	if group == "" {
+		group = os.Getenv("OTEL_SERVICE_GROUP")
+	}
+	if group == "" {
+		return nil
+	}
+
+	return []attribute.KeyValue{ServiceGroupKey.String(group)}
+}
+
+// IsSdkDisabled reports whether the SDK is disabled through OTEL_SDK_DISABLED.
+func IsSdkDisabled() bool {
+	return strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true")
+}
+
+// HeadersFromEnv parses OTLP headers from the given signal specific environment variable,
+// e.g. OTEL_EXPORTER_OTLP_TRACES_HEADERS, falling back to OTEL_EXPORTER_OTLP_HEADERS. The
+// value is a comma separated list of key=value pairs with URL encoded values.
+func HeadersFromEnv(signalEnv string) map[string]string {
+	value := os.Getenv(signalEnv)
+	if value == "" {
+		value = os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")
+	}
+
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+
+		k = strings.TrimSpace(k)
+		decoded, err := url.PathUnescape(strings.TrimSpace(v))
+		if k == "" || err != nil {
+			continue
+		}
+		headers[k] = decoded
+	}
+
+	return headers
+}
+
+// CompressionFromEnv returns the OTLP compression from the given signal specific environment
+// variable, e.g. OTEL_EXPORTER_OTLP_TRACES_COMPRESSION, falling back to OTEL_EXPORTER_OTLP_COMPRESSION.
+func CompressionFromEnv(signalEnv string) string {
+	if value := os.Getenv(signalEnv); value != "" {
+		return value
+	}
+
+	return os.Getenv("OTEL_EXPORTER_OTLP_COMPRESSION")
+}
+
+// ValidateCompression checks that compression is one of the values supported by the OTLP
+// exporters: empty, "none" or "gzip".
+func ValidateCompression(compression string) error {
+	switch compression {
+	case "", "none", "gzip":
+		return nil
+	default:
+		return fmt.Errorf("unsupported OTLP compression %q, expected \"none\" or \"gzip\"", compression)
+	}
+}
+
+// ValidateInterval checks that the duration option called name is not negative. Zero is valid
+// and conventionally selects the default.
+func ValidateInterval(name string, interval time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("%s: invalid duration %s, expected a positive value or zero for the default", name, interval)
+	}
+
+	return nil
+}
+
+// ValidateMinInterval checks that the duration option called name is at least min, rejecting
+// intervals short enough to flood the collector with exports.
+func ValidateMinInterval(name string, interval, min time.Duration) error {
+	if interval < min {
+		return fmt.Errorf("%s: interval %s is below the minimum of %s", name, interval, min)
+	}
+
+	return nil
+}
+
+// ValidateEndpoint checks that endpoint is either a host:port pair or an http or https URL with
+// a host, the two forms accepted by the OTLP exporters.
+func ValidateEndpoint(endpoint string) error {
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid OTLP endpoint %q: %w", endpoint, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid OTLP endpoint %q, expected an http or https URL with a host", endpoint)
+		}
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(endpoint); err != nil {
+		return fmt.Errorf("invalid OTLP endpoint %q, expected host:port or a URL: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+// ExportResultCallback is called after every export batch with the signal name ("traces",
+// "metrics" or "logs"), the number of items in the batch and the export error, if any.
+type ExportResultCallback func(signal string, count int, err error)
+
+// TimeoutFromEnv returns the OTLP export timeout from the given signal specific environment
+// variable, e.g. OTEL_EXPORTER_OTLP_TRACES_TIMEOUT, falling back to OTEL_EXPORTER_OTLP_TIMEOUT.
+// The value is in milliseconds, zero is returned when neither variable is set.
+func TimeoutFromEnv(signalEnv string) (time.Duration, error) {
+	name := signalEnv
+	value := os.Getenv(name)
+	if value == "" {
+		name = "OTEL_EXPORTER_OTLP_TIMEOUT"
+		value = os.Getenv(name)
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	ms, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("%s: invalid timeout %q, expected a positive number of milliseconds", name, value)
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// ErrShutdownTimeout is returned by the ShutdownWithTimeout functions when the provider did not
+// shut down in time. The error also matches context.DeadlineExceeded.
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// ShutdownWithTimeout calls shutdown with a context limited to timeout and returns no later than
+// the deadline, even if shutdown ignores its context. A timeout is reported as ErrShutdownTimeout,
+// any other error, e.g. a failed final export, is returned as is.
+func ShutdownWithTimeout(ctx context.Context, timeout time.Duration, shutdown func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- shutdown(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s: %w", ErrShutdownTimeout, timeout, err)
+		}
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w after %s: %w", ErrShutdownTimeout, timeout, ctx.Err())
+	}
+}
+
+// shutdownOnce records the single shutdown of a provider.
+type shutdownOnce struct {
+	once sync.Once
+	err  error
+}
+
+// shutdowns holds a shutdownOnce per provider whose ShutdownOnce call is in progress.
+var shutdowns sync.Map
+
+// ShutdownOnce calls shutdown for provider and returns its error, so a failed final export is
+// still reported. Concurrent calls, e.g. from layered deferred shutdowns, wait for the first one to
+// finish and return nil. Nothing is kept for the provider afterwards, so shutdown must do nothing
+// and return nil when called again for a provider already shut down.
+func ShutdownOnce(ctx context.Context, provider any, shutdown func(context.Context) error) error {
+	value, _ := shutdowns.LoadOrStore(provider, &shutdownOnce{})
+	s := value.(*shutdownOnce)
+
+	first := false
+	s.once.Do(func() {
+		first = true
+		s.err = shutdown(ctx)
+		shutdowns.CompareAndDelete(provider, s)
+	})
+	if !first {
+		return nil
+	}
+
+	return s.err
+}
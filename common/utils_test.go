@@ -0,0 +1,187 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdownOnce(t *testing.T) {
+	provider := new(int)
+	errShutdown := errors.New("final export failed")
+	release := make(chan struct{})
+
+	var calls atomic.Int32
+	shutdown := func(context.Context) error {
+		if calls.Add(1) > 1 {
+			return nil
+		}
+		<-release
+		return errShutdown
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- ShutdownOnce(context.Background(), provider, shutdown)
+		}()
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if errors.Is(err, errShutdown) {
+			failed++
+		} else if err != nil {
+			t.Errorf("ShutdownOnce error = %v", err)
+		}
+	}
+	if failed != 1 {
+		t.Errorf("%d calls returned the shutdown error, want exactly the first one", failed)
+	}
+
+	if _, ok := shutdowns.Load(provider); ok {
+		t.Error("provider is still tracked after its shutdown completed")
+	}
+
+	if err := ShutdownOnce(context.Background(), provider, shutdown); err != nil {
+		t.Errorf("ShutdownOnce after completion error = %v, want nil", err)
+	}
+}
+
+func TestHeadersFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		signal  string
+		generic string
+		want    map[string]string
+	}{
+		{name: "unset", want: map[string]string{}},
+		{name: "signal", signal: "Authorization=Bearer%20token,tenant=a", want: map[string]string{"Authorization": "Bearer token", "tenant": "a"}},
+		{name: "generic fallback", generic: "tenant=b", want: map[string]string{"tenant": "b"}},
+		{name: "signal over generic", signal: "tenant=a", generic: "tenant=b,other=c", want: map[string]string{"tenant": "a"}},
+		{name: "spaces", signal: " tenant = a , key=b ", want: map[string]string{"tenant": "a", "key": "b"}},
+		{name: "value with equals", signal: "token=a=b", want: map[string]string{"token": "a=b"}},
+		{name: "invalid pairs skipped", signal: "novalue,=empty,bad=%zz,ok=1", want: map[string]string{"ok": "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", tt.signal)
+			t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", tt.generic)
+
+			if got := HeadersFromEnv("OTEL_EXPORTER_OTLP_TRACES_HEADERS"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HeadersFromEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		signal  string
+		generic string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "signal", signal: "5000", want: 5 * time.Second},
+		{name: "generic fallback", generic: "250", want: 250 * time.Millisecond},
+		{name: "signal over generic", signal: "5000", generic: "250", want: 5 * time.Second},
+		{name: "spaces", signal: " 100 ", want: 100 * time.Millisecond},
+		{name: "zero", signal: "0", wantErr: true},
+		{name: "negative", generic: "-1", wantErr: true},
+		{name: "not a number", signal: "5s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", tt.signal)
+			t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", tt.generic)
+
+			got, err := TimeoutFromEnv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("TimeoutFromEnv() = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("TimeoutFromEnv() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShutdownWithTimeout(t *testing.T) {
+	exportErr := errors.New("export failed")
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name        string
+		shutdown    func(ctx context.Context) error
+		wantErr     error
+		wantTimeout bool
+	}{
+		{name: "success", shutdown: func(context.Context) error { return nil }},
+		{name: "export error", shutdown: func(context.Context) error { return exportErr }, wantErr: exportErr},
+		{name: "context honored", shutdown: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }, wantTimeout: true},
+		{name: "context ignored", shutdown: func(context.Context) error { <-release; return nil }, wantTimeout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := ShutdownWithTimeout(context.Background(), 100*time.Millisecond, tt.shutdown)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("ShutdownWithTimeout returned after %s, want no later than the 100ms deadline", elapsed)
+			}
+
+			if got := errors.Is(err, ErrShutdownTimeout); got != tt.wantTimeout {
+				t.Fatalf("ShutdownWithTimeout() = %v, timeout %t, want %t", err, got, tt.wantTimeout)
+			}
+			if tt.wantTimeout && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("ShutdownWithTimeout() = %v, want it to match context.DeadlineExceeded", err)
+			}
+			if !tt.wantTimeout && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ShutdownWithTimeout() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{endpoint: "collector:4317"},
+		{endpoint: "http://collector:4318"},
+		{endpoint: "https://collector:4318/v1/traces"},
+		{endpoint: "collector", wantErr: true},
+		{endpoint: "grpc://collector:4317", wantErr: true},
+		{endpoint: "http://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			if err := ValidateEndpoint(tt.endpoint); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEndpoint(%q) = %v, want error %t", tt.endpoint, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package common
+
+import "runtime/debug"
+
+// modulePath is the import path of the otelgo module.
+const modulePath = "github.com/wasilak/otelgo"
+
+// Version returns the version of the otelgo module linked into the binary, read from the build
+// information. It is empty when the version cannot be determined, e.g. in tests.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	if info.Main.Path == modulePath && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,126 @@
+package logs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	sdk "go.opentelemetry.io/otel/sdk/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// exporterTLSConfig returns the TLS configuration of the log exporter.
+func exporterTLSConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true, // WARNING: Skips certificate verification
+	}
+}
+
+// newExporter creates the OTLP log exporter selected by the environment.
+func newExporter(ctx context.Context, config OtelGoLogsConfig) (sdk.Exporter, error) {
+	grpcOpts := []otlploggrpc.Option{}
+	httpOpts := []otlploghttp.Option{}
+
+	timeout, err := common.TimeoutFromEnv("OTEL_EXPORTER_OTLP_LOGS_TIMEOUT")
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		grpcOpts = append(grpcOpts, otlploggrpc.WithTimeout(timeout))
+		httpOpts = append(httpOpts, otlploghttp.WithTimeout(timeout))
+	}
+
+	tlsConfig := exporterTLSConfig()
+
+	if common.IsOtlpProtocolGrpc("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL") {
+		if !config.ShareGRPCConn {
+			// Configure gRPC dial options to use the custom TLS configuration
+			grpcOpts = append(grpcOpts, otlploggrpc.WithDialOption(grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))))
+
+			return otlploggrpc.New(ctx, grpcOpts...)
+		}
+
+		// A shared connection is dialed with the TLS settings common to all signals.
+		creds, tlsKey, err := common.SharedGrpcCredentials(nil, "LOGS")
+		if err != nil {
+			return nil, err
+		}
+		dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+		// The exporter ignores its dial options when given a connection, so the compression it
+		// would have set up from the environment is part of the connection settings.
+		compression := common.CompressionFromEnv("OTEL_EXPORTER_OTLP_LOGS_COMPRESSION")
+		if compression == "gzip" {
+			dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor("gzip")))
+		}
+
+		options := fmt.Sprintf("%s,wait-for-ready=false,compression=%s", tlsKey, compression)
+		conn, release, err := common.AcquireGrpcConn(common.GrpcEndpoint("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"), options, dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		exporter, err := otlploggrpc.New(ctx, append(grpcOpts, otlploggrpc.WithGRPCConn(conn))...)
+		if err != nil {
+			_ = release()
+			return nil, err
+		}
+
+		return &releaseExporter{Exporter: exporter, release: release}, nil
+	}
+
+	httpOpts = append(httpOpts, otlploghttp.WithTLSClientConfig(tlsConfig))
+
+	return otlploghttp.New(ctx, httpOpts...)
+}
+
+// releaseExporter releases the shared connection of the wrapped exporter when it is shut down.
+type releaseExporter struct {
+	sdk.Exporter
+	release func() error
+}
+
+// Shutdown implements sdk.Exporter.
+func (e *releaseExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.release())
+}
+
+// callbackExporter reports the outcome of every export to an ExportResultCallback.
+type callbackExporter struct {
+	sdk.Exporter
+	callback common.ExportResultCallback
+}
+
+// Export implements sdk.Exporter.
+func (e *callbackExporter) Export(ctx context.Context, records []sdk.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	e.callback("logs", len(records), err)
+	return err
+}
+
+// debugExporter logs a summary of every export batch, using the body of the first record as its name.
+type debugExporter struct {
+	sdk.Exporter
+	logger *slog.Logger
+}
+
+// Export implements sdk.Exporter.
+func (e *debugExporter) Export(ctx context.Context, records []sdk.Record) error {
+	start := time.Now()
+	err := e.Exporter.Export(ctx, records)
+
+	first := ""
+	if len(records) > 0 {
+		first = records[0].Body().String()
+	}
+	common.LogExportBatch(ctx, e.logger, "logs", len(records), first, time.Since(start), err)
+
+	return err
+}
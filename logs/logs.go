@@ -3,98 +3,137 @@ package logs
 import (
 	"context"
 	"crypto/tls"
-	"os"
+	"log/slog"
+	"time"
 
 	"dario.cat/mergo"
 	"github.com/wasilak/otelgo/common"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
 	sdk "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/resource"
-	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 // OtelGoLogsConfig specifies the configuration for the OpenTelemetry logs.
 type OtelGoLogsConfig struct {
-	Attributes []attribute.KeyValue `json:"attributes"` // Attributes specifies the attributes to be added to the logger resource. Default is an empty slice.
+	Attributes               []attribute.KeyValue        `json:"attributes"`                 // Attributes specifies the attributes to be added to the logger resource. They extend the default service.name from OTEL_SERVICE_NAME, keys set here taking precedence. Default is an empty slice.
+	ReplaceDefaultAttributes bool                        `json:"replace_default_attributes"` // ReplaceDefaultAttributes specifies whether a non-empty Attributes replaces the default attributes instead of extending them. Default is false.
+	AttributeMap             map[string]string           `json:"attribute_map"`              // AttributeMap specifies additional string attributes to be added to the logger resource, combined with Attributes. Default is nil.
+	ServiceVersion           string                      `json:"service_version"`            // ServiceVersion specifies the service.version resource attribute, applied only when non-empty. Default is empty, leaving the version to OTEL_RESOURCE_ATTRIBUTES.
+	ServiceGroup             string                      `json:"service_group"`              // ServiceGroup specifies the service.group resource attribute naming the logical system the service belongs to. Default is empty, using OTEL_SERVICE_GROUP.
+	ResourceConfig           common.ResourceConfig       `json:"resource_config"`            // ResourceConfig specifies how the logger resource is detected. Default is all detectors enabled.
+	Resource                 *resource.Resource          `json:"-"`                          // Resource specifies a prebuilt resource used as is, e.g. one shared by all signals, in which case Attributes, AttributeMap, ServiceVersion, ServiceGroup and ResourceConfig are ignored. Default is nil, detecting the logger resource.
+	Disabled                 bool                        `json:"disabled"`                   // Disabled specifies whether the log records are neither collected nor exported, the programmatic equivalent of OTEL_LOGS_EXPORTER=none or OTEL_EXPORTER_OTLP_LOGS_PROTOCOL=none. Default is false.
+	DisableGlobal            bool                        `json:"disable_global"`             // DisableGlobal specifies whether Init leaves the global logger provider untouched. Default is false, setting the global provider.
+	ExportResultCallback     common.ExportResultCallback `json:"-"`                          // ExportResultCallback specifies a function called after every export batch with its size and error. Default is nil.
+	ExportDebugLogger        *slog.Logger                `json:"-"`                          // ExportDebugLogger specifies a logger receiving a debug level summary of every export batch, e.g. while debugging the collector. Default is nil, logging nothing. Its handler must not feed the logger provider, which would log every export in turn.
+	SyncErrorLogs            bool                        `json:"sync_error_logs"`            // SyncErrorLogs specifies whether records of ERROR severity and above are exported synchronously when emitted, best-effort, while lower severities are batched. Default is false, batching all records.
+	ShareGRPCConn            bool                        `json:"share_grpc_conn"`            // ShareGRPCConn specifies whether the gRPC log exporter shares one connection with the other otelgo exporters using the same endpoint and TLS settings, which follow OTEL_EXPORTER_OTLP_LOGS_INSECURE and the certificate variables unless set explicitly. Default is false, dialing a dedicated connection.
+	ExporterFactory          ExporterFactory             `json:"-"`                          // ExporterFactory specifies a function creating the log exporter in place of the OTLP exporter. Default is nil, using OTLP.
 }
 
+// ExporterFactory creates the log exporter used by Init, receiving the TLS configuration the OTLP
+// exporter would use. The exporter is shut down with the logger provider.
+type ExporterFactory func(ctx context.Context, tlsConfig *tls.Config) (sdk.Exporter, error)
+
 // defaultConfig specifies the default configuration for the OpenTelemetry logs.
-var defaultConfig = OtelGoLogsConfig{
-	Attributes: []attribute.KeyValue{
-		semconv.ServiceNameKey.String(os.Getenv("OTEL_SERVICE_NAME")),
-		semconv.ServiceVersionKey.String("v0.0.0"),
-	},
-}
+var defaultConfig = OtelGoLogsConfig{}
 
 // Init initializes an OpenTelemetry logger with a specified configuration.
 func Init(ctx context.Context, config OtelGoLogsConfig) (context.Context, *sdk.LoggerProvider, error) {
-	err := mergo.Merge(&defaultConfig, config, mergo.WithOverride)
+	// The caller's config is merged into a copy of defaultConfig, so settings never leak between calls.
+	localConfig := defaultConfig
+	err := mergo.Merge(&localConfig, config, mergo.WithOverride)
 	if err != nil {
 		return ctx, nil, err
 	}
 
-	res, err := resource.New(ctx,
-		resource.WithHost(),
-		resource.WithContainer(),
-		resource.WithProcess(),
-		resource.WithTelemetrySDK(),
-		resource.WithOS(),
-		resource.WithFromEnv(),
-		resource.WithAttributes(defaultConfig.Attributes...),
-	)
-	if err != nil {
-		return ctx, nil, err
+	// Caller attributes extend the default service.name, read from OTEL_SERVICE_NAME now rather
+	// than at package init, their keys taking precedence, unless they are meant to replace it.
+	if !localConfig.ReplaceDefaultAttributes || len(localConfig.Attributes) == 0 {
+		localConfig.Attributes = common.MergeAttributes(common.ServiceNameAttributes(), localConfig.Attributes)
 	}
 
-	var exporter sdk.Exporter
+	// User attributes are de-duplicated up front so the last value set for a key
+	// always wins, regardless of how the resource detectors order them.
+	attributes := common.MergeAttributes(localConfig.Attributes, common.AttributesFromMap(localConfig.AttributeMap), common.ServiceVersionAttributes(localConfig.ServiceVersion), common.ServiceGroupAttributes(localConfig.ServiceGroup))
 
-	if common.IsOtlpProtocolGrpc("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL") { // Create a custom TLS configuration
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // WARNING: This skips certificate verification!
+	// A prebuilt resource is used as is, skipping detection entirely.
+	res := localConfig.Resource
+	if res == nil {
+		res, err = common.NewResource(ctx, localConfig.ResourceConfig, attributes)
+		if err != nil {
+			return ctx, nil, err
 		}
+	}
 
-		// Configure gRPC dial options to use the custom TLS configuration
-		grpcOpts := []grpc.DialOption{
-			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	// A disabled signal gets a provider without processor, so no network setup is made, no
+	// background goroutine is started and Shutdown has nothing to flush.
+	if localConfig.Disabled || common.IsSignalDisabled("OTEL_LOGS_EXPORTER", "OTEL_EXPORTER_OTLP_LOGS_PROTOCOL") {
+		logProvider := sdk.NewLoggerProvider(sdk.WithResource(res))
+		if !localConfig.DisableGlobal {
+			global.SetLoggerProvider(logProvider)
 		}
+		return ctx, logProvider, nil
+	}
 
-		exporter, err = otlploggrpc.New(ctx, otlploggrpc.WithDialOption(grpcOpts...))
-		if err != nil {
-			return ctx, nil, err
-		}
+	var exporter sdk.Exporter
+	if localConfig.ExporterFactory != nil {
+		exporter, err = localConfig.ExporterFactory(ctx, exporterTLSConfig())
 	} else {
-		// Create a custom TLS configuration
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // WARNING: Skips certificate verification
-		}
-		exporter, err = otlploghttp.New(ctx, otlploghttp.WithTLSClientConfig(tlsConfig))
-		if err != nil {
-			return ctx, nil, err
-		}
+		exporter, err = newExporter(ctx, localConfig)
+	}
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	if localConfig.ExportResultCallback != nil {
+		exporter = &callbackExporter{Exporter: exporter, callback: localConfig.ExportResultCallback}
 	}
 
-	processor := sdk.NewBatchProcessor(exporter)
+	if localConfig.ExportDebugLogger != nil {
+		exporter = &debugExporter{Exporter: exporter, logger: localConfig.ExportDebugLogger}
+	}
+
+	var processor sdk.Processor = sdk.NewBatchProcessor(exporter)
+	if localConfig.SyncErrorLogs {
+		processor = newSeveritySplitProcessor(exporter, otellog.SeverityError)
+	}
 
 	logProvider := sdk.NewLoggerProvider(
 		sdk.WithResource(res),
 		sdk.WithProcessor(processor),
 	)
 
-	global.SetLoggerProvider(logProvider)
+	if !localConfig.DisableGlobal {
+		global.SetLoggerProvider(logProvider)
+	}
 
 	return ctx, logProvider, nil
 }
 
-// Shutdown closes the logger provider.
+// ForceFlush exports all log records buffered by the logger provider without shutting it down, e.g.
+// before a serverless instance is frozen. The provider remains usable afterwards.
+func ForceFlush(ctx context.Context, logProvider *sdk.LoggerProvider) error {
+	return logProvider.ForceFlush(ctx)
+}
+
+// Shutdown closes the logger provider. Only the first call for a provider does so, later calls
+// do nothing.
 func Shutdown(ctx context.Context, logProvider *sdk.LoggerProvider) {
 	defer func() {
-		if err := logProvider.Shutdown(ctx); err != nil {
+		if err := common.ShutdownOnce(ctx, logProvider, logProvider.Shutdown); err != nil {
 			panic(err)
 		}
 	}()
 }
+
+// ShutdownWithTimeout shuts down the logger provider like Shutdown, but returns instead of
+// panicking and never blocks longer than timeout, even when the collector is unreachable.
+// A shutdown cut short by the timeout returns an error matching common.ErrShutdownTimeout.
+func ShutdownWithTimeout(ctx context.Context, logProvider *sdk.LoggerProvider, timeout time.Duration) error {
+	return common.ShutdownWithTimeout(ctx, timeout, func(ctx context.Context) error {
+		return common.ShutdownOnce(ctx, logProvider, logProvider.Shutdown)
+	})
+}
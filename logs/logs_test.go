@@ -0,0 +1,301 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdk "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// memoryExporter keeps every exported record.
+type memoryExporter struct {
+	mu      sync.Mutex
+	records []sdk.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdk.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, record := range records {
+		e.records = append(e.records, record.Clone())
+	}
+
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error { return nil }
+
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+// initMemory initializes logs exporting into memory without touching the global provider.
+func initMemory(t *testing.T, config OtelGoLogsConfig) (*memoryExporter, *sdk.LoggerProvider) {
+	t.Helper()
+
+	exporter := &memoryExporter{}
+	config.DisableGlobal = true
+	config.ExporterFactory = func(context.Context, *tls.Config) (sdk.Exporter, error) {
+		return exporter, nil
+	}
+
+	_, logProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logProvider.Shutdown(context.Background()) })
+
+	return exporter, logProvider
+}
+
+func TestInitMergesDefaultAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceName string
+		resourceEnv string
+		config      OtelGoLogsConfig
+		want        map[attribute.Key]string
+	}{
+		{
+			name:        "name from OTEL_RESOURCE_ATTRIBUTES survives caller attributes",
+			resourceEnv: "service.name=orders",
+			config:      OtelGoLogsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "prod")}},
+			want:        map[attribute.Key]string{"service.name": "orders", "env": "prod"},
+		},
+		{
+			name:        "name from OTEL_SERVICE_NAME survives caller attributes",
+			serviceName: "payments",
+			config:      OtelGoLogsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "prod")}},
+			want:        map[attribute.Key]string{"service.name": "payments", "env": "prod"},
+		},
+		{
+			name:        "caller name takes precedence",
+			serviceName: "payments",
+			config:      OtelGoLogsConfig{Attributes: []attribute.KeyValue{attribute.String("service.name", "billing")}},
+			want:        map[attribute.Key]string{"service.name": "billing"},
+		},
+		{
+			name:   "attribute map merges with attributes",
+			config: OtelGoLogsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "prod")}, AttributeMap: map[string]string{"team": "payments", "region": "eu"}},
+			want:   map[attribute.Key]string{"env": "prod", "team": "payments", "region": "eu"},
+		},
+		{
+			name:   "last value of a repeated key wins",
+			config: OtelGoLogsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "dev"), attribute.String("team", "orders"), attribute.String("env", "prod")}},
+			want:   map[attribute.Key]string{"env": "prod", "team": "orders"},
+		},
+		{
+			name:   "service version",
+			config: OtelGoLogsConfig{ServiceVersion: "1.2.3"},
+			want:   map[attribute.Key]string{"service.version": "1.2.3"},
+		},
+		{
+			name:        "version from OTEL_RESOURCE_ATTRIBUTES is kept",
+			resourceEnv: "service.version=2.0.0",
+			want:        map[attribute.Key]string{"service.version": "2.0.0"},
+		},
+		{
+			name: "no version is injected",
+			want: map[attribute.Key]string{"service.version": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_SERVICE_NAME", tt.serviceName)
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", tt.resourceEnv)
+
+			exporter, logProvider := initMemory(t, tt.config)
+
+			var record log.Record
+			record.SetBody(log.StringValue("hello"))
+			logProvider.Logger("test").Emit(context.Background(), record)
+			if err := logProvider.ForceFlush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(exporter.records) != 1 {
+				t.Fatalf("got %d records, want 1", len(exporter.records))
+			}
+			res := exporter.records[0].Resource()
+			for key, want := range tt.want {
+				if got, _ := res.Set().Value(key); got.AsString() != want {
+					t.Errorf("%s = %q, want %q", key, got.AsString(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestInitDisableGlobal(t *testing.T) {
+	t.Setenv("OTEL_LOGS_EXPORTER", "none")
+
+	previous := global.GetLoggerProvider()
+	t.Cleanup(func() { global.SetLoggerProvider(previous) })
+
+	for _, disableGlobal := range []bool{true, false} {
+		sentinel := sdk.NewLoggerProvider()
+		global.SetLoggerProvider(sentinel)
+
+		_, logProvider, err := Init(context.Background(), OtelGoLogsConfig{DisableGlobal: disableGlobal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = logProvider.Shutdown(context.Background()) }()
+
+		want := log.LoggerProvider(logProvider)
+		if disableGlobal {
+			want = sentinel
+		}
+		if got := global.GetLoggerProvider(); got != want {
+			t.Errorf("DisableGlobal %t: global logger provider = %p, want %p", disableGlobal, got, want)
+		}
+	}
+}
+
+func TestForceFlush(t *testing.T) {
+	exporter, logProvider := initMemory(t, OtelGoLogsConfig{})
+
+	// The provider keeps exporting after a flush
+	for want := 1; want <= 2; want++ {
+		record := log.Record{}
+		record.SetBody(log.StringValue("message"))
+		logProvider.Logger("test").Emit(context.Background(), record)
+
+		if err := ForceFlush(context.Background(), logProvider); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(exporter.exported()); got != want {
+			t.Fatalf("exported %d records after flush %d, want %d", got, want, want)
+		}
+	}
+}
+
+func TestShutdownWithTimeoutStalledCollector(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stalled:
+		}
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	t.Setenv("OTEL_LOGS_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+
+	_, logProvider, err := Init(context.Background(), OtelGoLogsConfig{DisableGlobal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := log.Record{}
+	record.SetBody(log.StringValue("message"))
+	logProvider.Logger("test").Emit(context.Background(), record)
+
+	start := time.Now()
+	err = ShutdownWithTimeout(context.Background(), logProvider, 200*time.Millisecond)
+	if !errors.Is(err, common.ErrShutdownTimeout) {
+		t.Errorf("ShutdownWithTimeout() = %v, want ErrShutdownTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ShutdownWithTimeout returned after %s, want no later than the 200ms deadline", elapsed)
+	}
+}
+
+func TestInitResource(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+	res := resource.NewSchemaless(attribute.String("service.name", "orders"), attribute.String("team", "payments"))
+	exporter, logProvider := initMemory(t, OtelGoLogsConfig{
+		Resource:   res,
+		Attributes: []attribute.KeyValue{attribute.String("ignored", "true")},
+	})
+
+	var record log.Record
+	record.SetBody(log.StringValue("hello"))
+	logProvider.Logger("test").Emit(context.Background(), record)
+	if err := logProvider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(exporter.records))
+	}
+	if got := exporter.records[0].Resource(); !got.Equal(res) {
+		t.Errorf("log resource = %v, want the injected %v", got.Attributes(), res.Attributes())
+	}
+}
+
+func TestExportDebugLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, logProvider := initMemory(t, OtelGoLogsConfig{ExportDebugLogger: logger})
+	record := log.Record{}
+	record.SetBody(log.StringValue("order placed"))
+	logProvider.Logger("test").Emit(context.Background(), record)
+	if err := ForceFlush(context.Background(), logProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{`msg="otelgo export batch"`, "signal=logs", "count=1", `first="order placed"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestInitServiceGroup(t *testing.T) {
+	tests := []struct {
+		name  string
+		group string
+		env   string
+		want  string
+	}{
+		{name: "config", group: "checkout", want: "checkout"},
+		{name: "env fallback", env: "billing", want: "billing"},
+		{name: "config wins over env", group: "checkout", env: "billing", want: "checkout"},
+		{name: "unset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_SERVICE_GROUP", tt.env)
+
+			exporter, logProvider := initMemory(t, OtelGoLogsConfig{ServiceGroup: tt.group})
+			var record log.Record
+			record.SetBody(log.StringValue("hello"))
+			logProvider.Logger("test").Emit(context.Background(), record)
+			if err := logProvider.ForceFlush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(exporter.records) != 1 {
+				t.Fatalf("got %d records, want 1", len(exporter.records))
+			}
+			res := exporter.records[0].Resource()
+			if got, _ := res.Set().Value(common.ServiceGroupKey); got.AsString() != tt.want {
+				t.Errorf("log resource service.group = %q, want %q", got.AsString(), tt.want)
+			}
+			if tt.want == "" && res.Set().HasValue(common.ServiceGroupKey) {
+				t.Errorf("log resource has service.group without a group set")
+			}
+		})
+	}
+}
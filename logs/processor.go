@@ -0,0 +1,97 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdk "go.opentelemetry.io/otel/sdk/log"
+)
+
+// severitySplitProcessor exports records at or above threshold synchronously, so they are not
+// lost if the process crashes right after logging them, and batches all other records.
+// Both processors share the exporter through a serialExporter.
+type severitySplitProcessor struct {
+	threshold otellog.Severity
+	sync      sdk.Processor
+	batch     sdk.Processor
+}
+
+// newSeveritySplitProcessor returns a processor exporting records of at least threshold severity
+// synchronously and batching the others.
+func newSeveritySplitProcessor(exporter sdk.Exporter, threshold otellog.Severity) *severitySplitProcessor {
+	shared := &serialExporter{exporter: exporter}
+
+	return &severitySplitProcessor{
+		threshold: threshold,
+		sync:      sdk.NewSimpleProcessor(shared),
+		batch:     sdk.NewBatchProcessor(shared),
+	}
+}
+
+// OnEmit implements sdk.Processor. A failed synchronous export is best-effort and returned like
+// any other processor error.
+func (p *severitySplitProcessor) OnEmit(ctx context.Context, record *sdk.Record) error {
+	if record.Severity() >= p.threshold {
+		return p.sync.OnEmit(ctx, record)
+	}
+
+	return p.batch.OnEmit(ctx, record)
+}
+
+// Shutdown implements sdk.Processor.
+func (p *severitySplitProcessor) Shutdown(ctx context.Context) error {
+	return errors.Join(p.sync.Shutdown(ctx), p.batch.Shutdown(ctx))
+}
+
+// ForceFlush implements sdk.Processor.
+func (p *severitySplitProcessor) ForceFlush(ctx context.Context) error {
+	return errors.Join(p.sync.ForceFlush(ctx), p.batch.ForceFlush(ctx))
+}
+
+// serialExporter lets two processors share an exporter, which sdk.Exporter does not require to
+// be safe for concurrent use, by serializing its calls. Both processors shut it down, only the
+// first call reaches the exporter and later calls do nothing.
+type serialExporter struct {
+	mu       sync.Mutex
+	exporter sdk.Exporter
+	stopped  bool
+}
+
+// Export implements sdk.Exporter.
+func (e *serialExporter) Export(ctx context.Context, records []sdk.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return nil
+	}
+
+	return e.exporter.Export(ctx, records)
+}
+
+// ForceFlush implements sdk.Exporter.
+func (e *serialExporter) ForceFlush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return nil
+	}
+
+	return e.exporter.ForceFlush(ctx)
+}
+
+// Shutdown implements sdk.Exporter.
+func (e *serialExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return nil
+	}
+	e.stopped = true
+
+	return e.exporter.Shutdown(ctx)
+}
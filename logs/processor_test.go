@@ -0,0 +1,117 @@
+package logs
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdk "go.opentelemetry.io/otel/sdk/log"
+)
+
+// exported returns the bodies of the records exported so far.
+func (e *memoryExporter) exported() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bodies := []string{}
+	for _, record := range e.records {
+		bodies = append(bodies, record.Body().AsString())
+	}
+
+	return bodies
+}
+
+func TestSyncErrorLogs(t *testing.T) {
+	exporter, logProvider := initMemory(t, OtelGoLogsConfig{SyncErrorLogs: true})
+	logger := logProvider.Logger("test")
+
+	emit := func(severity otellog.Severity, body string) {
+		record := otellog.Record{}
+		record.SetSeverity(severity)
+		record.SetBody(otellog.StringValue(body))
+		logger.Emit(context.Background(), record)
+	}
+
+	emit(otellog.SeverityInfo, "queued")
+	emit(otellog.SeverityError, "failed")
+
+	if got := exporter.exported(); len(got) != 1 || got[0] != "failed" {
+		t.Fatalf("exported before flush = %v, want only the error record", got)
+	}
+
+	if err := logProvider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := exporter.exported(); len(got) != 2 || got[1] != "queued" {
+		t.Errorf("exported after flush = %v, want the info record batched after the error record", got)
+	}
+}
+
+// serialCheckExporter records concurrent calls and how often it was shut down.
+type serialCheckExporter struct {
+	active     atomic.Int32
+	concurrent atomic.Bool
+	shutdowns  atomic.Int32
+}
+
+// call marks a call as running until the returned function is called.
+func (e *serialCheckExporter) call() func() {
+	if e.active.Add(1) > 1 {
+		e.concurrent.Store(true)
+	}
+	runtime.Gosched()
+
+	return func() { e.active.Add(-1) }
+}
+
+func (e *serialCheckExporter) Export(context.Context, []sdk.Record) error {
+	defer e.call()()
+	return nil
+}
+
+func (e *serialCheckExporter) ForceFlush(context.Context) error {
+	defer e.call()()
+	return nil
+}
+
+func (e *serialCheckExporter) Shutdown(context.Context) error {
+	defer e.call()()
+	e.shutdowns.Add(1)
+	return nil
+}
+
+func TestSeveritySplitProcessorSharesExporter(t *testing.T) {
+	exporter := &serialCheckExporter{}
+	processor := newSeveritySplitProcessor(exporter, otellog.SeverityError)
+
+	var wg sync.WaitGroup
+	for _, severity := range []otellog.Severity{otellog.SeverityInfo, otellog.SeverityError} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				record := sdk.Record{}
+				record.SetSeverity(severity)
+				_ = processor.OnEmit(context.Background(), &record)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := processor.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if exporter.concurrent.Load() {
+		t.Error("exporter was called concurrently")
+	}
+	if got := exporter.shutdowns.Load(); got != 1 {
+		t.Errorf("exporter shut down %d times, want 1", got)
+	}
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const (
+	// defaultExportInterval is the periodic reader default, used when OTEL_METRIC_EXPORT_INTERVAL is not set.
+	defaultExportInterval = 60 * time.Second
+	// defaultExportTimeout is the time limit of a single aligned export when ExportTimeout is not set.
+	defaultExportTimeout = 30 * time.Second
+)
+
+// exportIntervalFromEnv returns the export interval from OTEL_METRIC_EXPORT_INTERVAL, in
+// milliseconds, or the default of 60 seconds.
+func exportIntervalFromEnv() (time.Duration, error) {
+	value := os.Getenv("OTEL_METRIC_EXPORT_INTERVAL")
+	if value == "" {
+		return defaultExportInterval, nil
+	}
+
+	ms, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("OTEL_METRIC_EXPORT_INTERVAL: invalid interval %q, expected a positive number of milliseconds", value)
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// alignedReader collects and exports metrics at multiples of the interval on the wall clock,
+// e.g. at :00, :15, :30 and :45 seconds for a 15 second interval, instead of relative to the
+// start of the process like sdk.PeriodicReader.
+type alignedReader struct {
+	*sdk.ManualReader
+
+	exporter sdk.Exporter
+	interval time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+
+	// mu serializes collection and export between the loop, ForceFlush and Shutdown, as exporters
+	// are not required to be safe for concurrent use.
+	mu sync.Mutex
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// newAlignedReader returns a reader exporting to exporter at interval boundaries, each export
+// limited to timeout, and starts its collection loop.
+func newAlignedReader(exporter sdk.Exporter, interval, timeout time.Duration) *alignedReader {
+	return startAlignedReader(exporter, interval, timeout, time.Now)
+}
+
+// startAlignedReader is newAlignedReader with the clock the boundaries are computed from.
+func startAlignedReader(exporter sdk.Exporter, interval, timeout time.Duration, now func() time.Time) *alignedReader {
+	r := &alignedReader{
+		ManualReader: sdk.NewManualReader(
+			sdk.WithTemporalitySelector(exporter.Temporality),
+			sdk.WithAggregationSelector(exporter.Aggregation),
+		),
+		exporter: exporter,
+		interval: interval,
+		timeout:  timeout,
+		now:      now,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go r.run()
+
+	return r
+}
+
+// nextBoundary returns the first multiple of interval after t.
+func nextBoundary(t time.Time, interval time.Duration) time.Time {
+	return t.Truncate(interval).Add(interval)
+}
+
+// run exports at every interval boundary until the reader is shut down.
+func (r *alignedReader) run() {
+	defer close(r.stopped)
+
+	for {
+		now := r.now()
+		timer := time.NewTimer(nextBoundary(now, r.interval).Sub(now))
+
+		select {
+		case <-timer.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+			r.mu.Lock()
+			err := r.export(ctx)
+			r.mu.Unlock()
+			if err != nil {
+				otel.Handle(err)
+			}
+			cancel()
+		case <-r.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// export collects the current metrics and passes them to the exporter. It must be called with mu
+// held.
+func (r *alignedReader) export(ctx context.Context) error {
+	rm := metricdata.ResourceMetrics{}
+	if err := r.Collect(ctx, &rm); err != nil {
+		return err
+	}
+
+	return r.exporter.Export(ctx, &rm)
+}
+
+// ForceFlush implements sdk.Reader, exporting the current metrics immediately.
+func (r *alignedReader) ForceFlush(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return errors.Join(r.export(ctx), r.exporter.ForceFlush(ctx))
+}
+
+// Shutdown implements sdk.Reader. It stops the collection loop, exports the metrics collected
+// since the last boundary and shuts down the exporter.
+func (r *alignedReader) Shutdown(ctx context.Context) error {
+	err := sdk.ErrReaderShutdown
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		<-r.stopped
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		err = errors.Join(r.export(ctx), r.ManualReader.Shutdown(ctx), r.exporter.Shutdown(ctx))
+	})
+
+	return err
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// recordingExporter records the clock reading of every export and whether exports overlapped.
+type recordingExporter struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	exports []time.Time
+
+	inflight   atomic.Int32
+	overlapped atomic.Bool
+}
+
+func (e *recordingExporter) Temporality(kind sdk.InstrumentKind) metricdata.Temporality {
+	return sdk.DefaultTemporalitySelector(kind)
+}
+
+func (e *recordingExporter) Aggregation(kind sdk.InstrumentKind) sdk.Aggregation {
+	return sdk.DefaultAggregationSelector(kind)
+}
+
+func (e *recordingExporter) Export(context.Context, *metricdata.ResourceMetrics) error {
+	if e.inflight.Add(1) > 1 {
+		e.overlapped.Store(true)
+	}
+	defer e.inflight.Add(-1)
+	time.Sleep(time.Millisecond)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exports = append(e.exports, e.now())
+
+	return nil
+}
+
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func (e *recordingExporter) Shutdown(context.Context) error { return nil }
+
+func (e *recordingExporter) times() []time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]time.Time(nil), e.exports...)
+}
+
+func TestNextBoundary(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		interval time.Duration
+		want     time.Time
+	}{
+		{"mid interval", base.Add(7 * time.Second), 15 * time.Second, base.Add(15 * time.Second)},
+		{"on boundary", base.Add(15 * time.Second), 15 * time.Second, base.Add(30 * time.Second)},
+		{"just before boundary", base.Add(59*time.Second + 999*time.Millisecond), time.Minute, base.Add(time.Minute)},
+		{"sub second", base.Add(1250 * time.Millisecond), 500 * time.Millisecond, base.Add(1500 * time.Millisecond)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextBoundary(tt.now, tt.interval); !got.Equal(tt.want) {
+				t.Errorf("nextBoundary(%s, %s) = %s, want %s", tt.now.Format(time.StampMilli), tt.interval, got.Format(time.StampMilli), tt.want.Format(time.StampMilli))
+			}
+		})
+	}
+}
+
+func TestAlignedReaderFirstExportAtBoundary(t *testing.T) {
+	// The injected clock starts 30ms before the :15 boundary of a 15 second interval.
+	boundary := time.Date(2026, 1, 1, 12, 0, 15, 0, time.UTC)
+	start := time.Now()
+	clock := func() time.Time { return boundary.Add(-30 * time.Millisecond).Add(time.Since(start)) }
+
+	exporter := &recordingExporter{now: clock}
+	reader := startAlignedReader(exporter, 15*time.Second, time.Second, clock)
+	sdk.NewMeterProvider(sdk.WithReader(reader))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(exporter.times()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no export within 5s of the boundary")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	first := exporter.times()[0]
+	if first.Before(boundary) || first.After(boundary.Add(time.Second)) {
+		t.Errorf("first export at %s, want at the %s boundary", first.Format(time.StampMilli), boundary.Format(time.StampMilli))
+	}
+
+	if err := reader.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAlignedReaderSerializesExports(t *testing.T) {
+	exporter := &recordingExporter{now: time.Now}
+	reader := newAlignedReader(exporter, time.Millisecond, time.Second)
+	sdk.NewMeterProvider(sdk.WithReader(reader))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_ = reader.ForceFlush(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := reader.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if exporter.overlapped.Load() {
+		t.Error("Export was called concurrently")
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+)
+
+// Exemplar filters supported by OtelGoMetricsConfig.ExemplarFilter, with the meaning of
+// OTEL_METRICS_EXEMPLAR_FILTER.
+const (
+	ExemplarFilterAlwaysOff  = "always_off"
+	ExemplarFilterAlwaysOn   = "always_on"
+	ExemplarFilterTraceBased = "trace_based"
+)
+
+// exemplarFilter returns the filter selected by ExemplarFilter or OTEL_METRICS_EXEMPLAR_FILTER, or
+// nil when neither is set and the SDK keeps its trace based default. Unlike the SDK, which ignores
+// an unknown filter, it is reported as an error.
+func exemplarFilter(config OtelGoMetricsConfig) (exemplar.Filter, error) {
+	name, source := config.ExemplarFilter, "ExemplarFilter"
+	if name == "" {
+		name, source = os.Getenv("OTEL_METRICS_EXEMPLAR_FILTER"), "OTEL_METRICS_EXEMPLAR_FILTER"
+	}
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return nil, nil
+	case ExemplarFilterAlwaysOff:
+		return exemplar.AlwaysOffFilter, nil
+	case ExemplarFilterAlwaysOn:
+		return exemplar.AlwaysOnFilter, nil
+	case ExemplarFilterTraceBased:
+		return exemplar.TraceBasedFilter, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported exemplar filter %q, expected %s, %s or %s", source, name, ExemplarFilterAlwaysOff, ExemplarFilterAlwaysOn, ExemplarFilterTraceBased)
+	}
+}
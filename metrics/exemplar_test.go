@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestInitExemplarFilter(t *testing.T) {
+	traceID := oteltrace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := oteltrace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+
+	tests := []struct {
+		name    string
+		env     string
+		filter  string
+		sampled bool
+		want    int
+	}{
+		{name: "default records sampled spans", sampled: true, want: 1},
+		{name: "trace based sampled", filter: ExemplarFilterTraceBased, sampled: true, want: 1},
+		{name: "trace based unsampled", filter: ExemplarFilterTraceBased},
+		{name: "always on unsampled", filter: ExemplarFilterAlwaysOn, want: 1},
+		{name: "always off sampled", filter: ExemplarFilterAlwaysOff, sampled: true},
+		{name: "always off from env", env: "always_off", sampled: true},
+		{name: "config wins over env", env: "always_off", filter: ExemplarFilterAlwaysOn, sampled: true, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_METRICS_EXEMPLAR_FILTER", tt.env)
+			reader, meterProvider := initReader(t, OtelGoMetricsConfig{ExemplarFilter: tt.filter})
+
+			var flags oteltrace.TraceFlags
+			if tt.sampled {
+				flags = oteltrace.FlagsSampled
+			}
+			spanContext := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: flags})
+			ctx := oteltrace.ContextWithSpanContext(context.Background(), spanContext)
+
+			histogram, err := meterProvider.Meter("test").Float64Histogram("request.duration")
+			if err != nil {
+				t.Fatal(err)
+			}
+			histogram.Record(ctx, 0.3)
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+			exemplars := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64]).DataPoints[0].Exemplars
+			if len(exemplars) != tt.want {
+				t.Fatalf("got %d exemplars, want %d", len(exemplars), tt.want)
+			}
+			if tt.want > 0 && tt.sampled {
+				if got := oteltrace.TraceID(exemplars[0].TraceID); got != traceID {
+					t.Errorf("exemplar trace id = %s, want %s", got, traceID)
+				}
+			}
+		})
+	}
+}
+
+func TestInitRejectsUnknownExemplarFilter(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	tests := []struct {
+		name   string
+		env    string
+		filter string
+	}{
+		{name: "config", filter: "sometimes"},
+		{name: "env", env: "sometimes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_METRICS_EXEMPLAR_FILTER", tt.env)
+
+			config := OtelGoMetricsConfig{ExemplarFilter: tt.filter, DisableGlobal: true, Readers: []sdk.Reader{sdk.NewManualReader()}}
+			if _, _, err := Init(context.Background(), config); err == nil {
+				t.Error("Init succeeded with an unknown exemplar filter, want an error")
+			}
+		})
+	}
+}
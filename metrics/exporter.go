@@ -0,0 +1,256 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+)
+
+// consoleOutput is where the console exporter writes metrics.
+var consoleOutput io.Writer = os.Stdout
+
+// newExporter creates the metric exporter selected by the configuration and environment.
+func newExporter(ctx context.Context, config OtelGoMetricsConfig) (sdk.Exporter, error) {
+	// The console exporter writes metrics to stdout for local development and never touches the network.
+	if config.ConsoleExporter || os.Getenv("OTEL_METRICS_EXPORTER") == "console" {
+		opts := []stdoutmetric.Option{stdoutmetric.WithWriter(consoleOutput)}
+		if config.ConsolePrettyPrint {
+			opts = append(opts, stdoutmetric.WithPrettyPrint())
+		}
+
+		return stdoutmetric.New(opts...)
+	}
+
+	grpcOpts := []otlpmetricgrpc.Option{}
+	httpOpts := []otlpmetrichttp.Option{}
+
+	// A configured endpoint takes precedence over the OTEL_EXPORTER_OTLP_*ENDPOINT variables
+	if config.Endpoint != "" {
+		if err := common.ValidateEndpoint(config.Endpoint); err != nil {
+			return nil, fmt.Errorf("Endpoint: %w", err)
+		}
+
+		if strings.Contains(config.Endpoint, "://") {
+			grpcOpts = append(grpcOpts, otlpmetricgrpc.WithEndpointURL(config.Endpoint))
+			httpOpts = append(httpOpts, otlpmetrichttp.WithEndpointURL(config.Endpoint))
+		} else {
+			grpcOpts = append(grpcOpts, otlpmetricgrpc.WithEndpoint(config.Endpoint))
+			httpOpts = append(httpOpts, otlpmetrichttp.WithEndpoint(config.Endpoint))
+		}
+	}
+	if config.EndpointURLPath != "" {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithURLPath(config.EndpointURLPath))
+	}
+
+	headers := config.Headers
+	if len(headers) == 0 {
+		headers = common.HeadersFromEnv("OTEL_EXPORTER_OTLP_METRICS_HEADERS")
+	}
+	if len(headers) > 0 {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithHeaders(headers))
+		httpOpts = append(httpOpts, otlpmetrichttp.WithHeaders(headers))
+	}
+
+	timeout, err := common.TimeoutFromEnv("OTEL_EXPORTER_OTLP_METRICS_TIMEOUT")
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithTimeout(timeout))
+		httpOpts = append(httpOpts, otlpmetrichttp.WithTimeout(timeout))
+	}
+
+	compression := config.Compression
+	if compression == "" {
+		compression = common.CompressionFromEnv("OTEL_EXPORTER_OTLP_METRICS_COMPRESSION")
+	}
+	if err := common.ValidateCompression(compression); err != nil {
+		return nil, err
+	}
+
+	switch compression {
+	case "gzip":
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithCompressor("gzip"))
+		httpOpts = append(httpOpts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	case "none":
+		// gRPC has no compressor named "none", sending uncompressed is its default.
+		httpOpts = append(httpOpts, otlpmetrichttp.WithCompression(otlpmetrichttp.NoCompression))
+	}
+
+	temporality, err := temporalitySelector(config)
+	if err != nil {
+		return nil, err
+	}
+	if temporality != nil {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithTemporalitySelector(temporality))
+		httpOpts = append(httpOpts, otlpmetrichttp.WithTemporalitySelector(temporality))
+	}
+
+	if common.IsOtlpProtocolGrpc("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL") {
+		if !config.ShareGRPCConn {
+			return otlpmetricgrpc.New(ctx, grpcOpts...)
+		}
+
+		// A shared connection is dialed with the TLS settings common to all signals and the
+		// configured compression, which the exporter would otherwise set on its own dial.
+		creds, tlsKey, err := common.SharedGrpcCredentials(nil, "METRICS")
+		if err != nil {
+			return nil, err
+		}
+		dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+		if compression == "gzip" {
+			dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor("gzip")))
+		}
+
+		options := fmt.Sprintf("%s,wait-for-ready=false,compression=%s", tlsKey, compression)
+		target := common.GrpcEndpoint("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT")
+		if config.Endpoint != "" {
+			target = common.GrpcTarget(config.Endpoint)
+		}
+
+		conn, release, err := common.AcquireGrpcConn(target, options, dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		exporter, err := otlpmetricgrpc.New(ctx, append(grpcOpts, otlpmetricgrpc.WithGRPCConn(conn))...)
+		if err != nil {
+			_ = release()
+			return nil, err
+		}
+
+		return &releaseExporter{Exporter: exporter, release: release}, nil
+	}
+
+	return otlpmetrichttp.New(ctx, httpOpts...)
+}
+
+// releaseExporter releases the shared connection of the wrapped exporter when it is shut down.
+type releaseExporter struct {
+	sdk.Exporter
+	release func() error
+}
+
+// Shutdown implements sdk.Exporter.
+func (e *releaseExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.release())
+}
+
+// callbackExporter reports the outcome of every export to an ExportResultCallback.
+type callbackExporter struct {
+	sdk.Exporter
+	callback common.ExportResultCallback
+}
+
+// Export implements sdk.Exporter. The reported count is the number of metrics in the batch.
+func (e *callbackExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+
+	count := 0
+	for _, sm := range rm.ScopeMetrics {
+		count += len(sm.Metrics)
+	}
+	e.callback("metrics", count, err)
+
+	return err
+}
+
+// debugExporter logs a summary of every export batch.
+type debugExporter struct {
+	sdk.Exporter
+	logger *slog.Logger
+}
+
+// Export implements sdk.Exporter. The reported count is the number of metrics in the batch.
+func (e *debugExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	start := time.Now()
+	err := e.Exporter.Export(ctx, rm)
+
+	count, first := 0, ""
+	for _, sm := range rm.ScopeMetrics {
+		if first == "" && len(sm.Metrics) > 0 {
+			first = sm.Metrics[0].Name
+		}
+		count += len(sm.Metrics)
+	}
+	common.LogExportBatch(ctx, e.logger, "metrics", count, first, time.Since(start), err)
+
+	return err
+}
+
+// attributesExporter adds default attributes to every data point before export. Views can only
+// filter attributes, so this is done on the exported data instead.
+type attributesExporter struct {
+	sdk.Exporter
+	attributes []attribute.KeyValue
+}
+
+// Export implements sdk.Exporter. Attributes recorded with a measurement take precedence over
+// the default ones.
+func (e *attributesExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for i := range rm.ScopeMetrics {
+		for j := range rm.ScopeMetrics[i].Metrics {
+			m := &rm.ScopeMetrics[i].Metrics[j]
+
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.Sum[float64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.Gauge[int64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.Gauge[float64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.Histogram[int64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.Histogram[float64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.ExponentialHistogram[int64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.ExponentialHistogram[float64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			case metricdata.Summary:
+				for k := range data.DataPoints {
+					data.DataPoints[k].Attributes = e.merge(data.DataPoints[k].Attributes)
+				}
+			}
+		}
+	}
+
+	return e.Exporter.Export(ctx, rm)
+}
+
+// merge returns set extended with the default attributes.
+func (e *attributesExporter) merge(set attribute.Set) attribute.Set {
+	return attribute.NewSet(common.MergeAttributes(e.attributes, set.ToSlice())...)
+}
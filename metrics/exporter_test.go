@@ -0,0 +1,402 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// memoryExporter keeps the metrics of every export.
+type memoryExporter struct {
+	mu      sync.Mutex
+	exports []metricdata.ResourceMetrics
+}
+
+func (e *memoryExporter) Temporality(kind sdk.InstrumentKind) metricdata.Temporality {
+	return sdk.DefaultTemporalitySelector(kind)
+}
+
+func (e *memoryExporter) Aggregation(kind sdk.InstrumentKind) sdk.Aggregation {
+	return sdk.DefaultAggregationSelector(kind)
+}
+
+func (e *memoryExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exports = append(e.exports, *rm)
+	return nil
+}
+
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func (e *memoryExporter) Shutdown(context.Context) error { return nil }
+
+// exportCounter adds value with opts to a counter of a provider initialized with config, flushes
+// it through a memoryExporter and returns the exported data points of the counter.
+func exportCounter(t *testing.T, config OtelGoMetricsConfig, value int64, opts ...metric.AddOption) []metricdata.DataPoint[int64] {
+	t.Helper()
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+
+	exporter := &memoryExporter{}
+	config.DisableGlobal = true
+	config.ExporterFactory = func(context.Context, *tls.Config) (sdk.Exporter, error) {
+		return exporter, nil
+	}
+
+	_, meterProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), meterProvider) }()
+
+	counter, err := meterProvider.Meter("test").Int64Counter("requests.total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Add(context.Background(), value, opts...)
+	if err := meterProvider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	for _, rm := range exporter.exports {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == "requests.total" {
+					return m.Data.(metricdata.Sum[int64]).DataPoints
+				}
+			}
+		}
+	}
+	t.Fatal("requests.total was not exported")
+
+	return nil
+}
+
+func TestDefaultAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs []attribute.KeyValue
+		want  map[attribute.Key]string
+	}{
+		{name: "default attribute", want: map[attribute.Key]string{"deployment.environment": "production"}},
+		{name: "recorded attribute", attrs: []attribute.KeyValue{attribute.String("route", "/orders")}, want: map[attribute.Key]string{"deployment.environment": "production", "route": "/orders"}},
+		{name: "recorded attribute wins", attrs: []attribute.KeyValue{attribute.String("deployment.environment", "canary")}, want: map[attribute.Key]string{"deployment.environment": "canary"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := OtelGoMetricsConfig{DefaultAttributes: []attribute.KeyValue{attribute.String("deployment.environment", "production")}}
+			points := exportCounter(t, config, 1, metric.WithAttributes(tt.attrs...))
+			if len(points) != 1 {
+				t.Fatalf("exported %d data points, want 1", len(points))
+			}
+
+			set := points[0].Attributes
+			if set.Len() != len(tt.want) {
+				t.Errorf("data point attributes = %v, want %v", set.ToSlice(), tt.want)
+			}
+			for key, want := range tt.want {
+				if got, _ := set.Value(key); got.AsString() != want {
+					t.Errorf("%s = %q, want %q", key, got.AsString(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestForceFlush(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+
+	exporter := &memoryExporter{}
+	_, meterProvider, err := Init(context.Background(), OtelGoMetricsConfig{
+		DisableGlobal: true,
+		ExporterFactory: func(context.Context, *tls.Config) (sdk.Exporter, error) {
+			return exporter, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), meterProvider) }()
+
+	counter, err := meterProvider.Meter("test").Int64Counter("requests.total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The provider keeps exporting after a flush
+	for want := int64(1); want <= 2; want++ {
+		counter.Add(context.Background(), 1)
+		if err := ForceFlush(context.Background(), meterProvider); err != nil {
+			t.Fatal(err)
+		}
+
+		exporter.mu.Lock()
+		last := exporter.exports[len(exporter.exports)-1]
+		exporter.mu.Unlock()
+		points := last.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints
+		if len(points) != 1 || points[0].Value != want {
+			t.Fatalf("exported %+v after flush %d, want a count of %d", points, want, want)
+		}
+	}
+}
+
+func TestExportDebugLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	exportCounter(t, OtelGoMetricsConfig{ExportDebugLogger: logger}, 1)
+
+	got := buf.String()
+	for _, want := range []string{`msg="otelgo export batch"`, "signal=metrics", "count=1", "first=requests.total"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestConsoleExporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		exporterEnv string
+		config      OtelGoMetricsConfig
+		want        []string
+	}{
+		{name: "environment", exporterEnv: "console", want: []string{`"Name":"orders.placed"`, `"Value":3`}},
+		{name: "config", config: OtelGoMetricsConfig{ConsoleExporter: true}, want: []string{`"Name":"orders.placed"`, `"Value":3`}},
+		{name: "pretty print", config: OtelGoMetricsConfig{ConsoleExporter: true, ConsolePrettyPrint: true}, want: []string{`"Name": "orders.placed"`, `"Value": 3`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_METRICS_EXPORTER", tt.exporterEnv)
+
+			output := &bytes.Buffer{}
+			consoleOutput = output
+			defer func() { consoleOutput = os.Stdout }()
+
+			config := tt.config
+			config.DisableGlobal = true
+			_, meterProvider, err := Init(context.Background(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = shutdown(context.Background(), meterProvider) }()
+
+			counter, err := meterProvider.Meter("test").Int64Counter("orders.placed")
+			if err != nil {
+				t.Fatal(err)
+			}
+			counter.Add(context.Background(), 3)
+			if err := ForceFlush(context.Background(), meterProvider); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(output.String(), want) {
+					t.Errorf("console output = %s, want it to contain %s", output, want)
+				}
+			}
+		})
+	}
+}
+
+// exportOneMetric exports a counter with the exporter newExporter creates for config.
+func exportOneMetric(t *testing.T, config OtelGoMetricsConfig) error {
+	t.Helper()
+
+	exporter, err := newExporter(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{{
+			Name: "requests.total",
+			Data: metricdata.Sum[int64]{
+				Temporality: metricdata.CumulativeTemporality,
+				IsMonotonic: true,
+				DataPoints:  []metricdata.DataPoint[int64]{{Value: 1}},
+			},
+		}},
+	}}}
+
+	return exporter.Export(context.Background(), rm)
+}
+
+func TestHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config OtelGoMetricsConfig
+		want   string
+	}{
+		{name: "config", config: OtelGoMetricsConfig{Headers: map[string]string{"X-Api-Key": "from-config"}}, want: "from-config"},
+		{name: "metrics environment", env: map[string]string{"OTEL_EXPORTER_OTLP_METRICS_HEADERS": "X-Api-Key=from-metrics-env"}, want: "from-metrics-env"},
+		{name: "generic environment", env: map[string]string{"OTEL_EXPORTER_OTLP_HEADERS": "X-Api-Key=from-env"}, want: "from-env"},
+		{
+			name:   "config over environment",
+			env:    map[string]string{"OTEL_EXPORTER_OTLP_METRICS_HEADERS": "X-Api-Key=from-metrics-env"},
+			config: OtelGoMetricsConfig{Headers: map[string]string{"X-Api-Key": "from-config"}},
+			want:   "from-config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := make(chan http.Header, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case headers <- r.Header.Clone():
+				default:
+				}
+			}))
+			defer server.Close()
+
+			t.Setenv("OTEL_METRICS_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+			t.Setenv("OTEL_EXPORTER_OTLP_METRICS_HEADERS", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			config := tt.config
+			config.Endpoint = server.URL
+			if err := exportOneMetric(t, config); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := (<-headers).Get("X-Api-Key"); got != tt.want {
+				t.Errorf("X-Api-Key header = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		urlPath  string
+		fromEnv  bool
+		wantPath string
+	}{
+		{name: "endpoint without environment", wantPath: "/v1/metrics"},
+		{name: "endpoint with path", urlPath: "/otlp/v1/metrics", wantPath: "/otlp/v1/metrics"},
+		{name: "endpoint over environment", fromEnv: true, wantPath: "/v1/metrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := make(chan string, 1)
+			configured := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths <- r.URL.Path
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer configured.Close()
+
+			var envRequests atomic.Int32
+			fromEnv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				envRequests.Add(1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer fromEnv.Close()
+
+			// Only the configuration selects the collector, unless the test sets the environment
+			for _, name := range []string{"OTEL_METRICS_EXPORTER", "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL", "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"} {
+				t.Setenv(name, "")
+			}
+			if tt.fromEnv {
+				t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", fromEnv.URL+"/v1/metrics")
+			}
+
+			if err := exportOneMetric(t, OtelGoMetricsConfig{Endpoint: configured.URL, EndpointURLPath: tt.urlPath}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := <-paths; got != tt.wantPath {
+				t.Errorf("configured endpoint received %s, want %s", got, tt.wantPath)
+			}
+			if got := envRequests.Load(); got != 0 {
+				t.Errorf("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT received %d requests, want none", got)
+			}
+		})
+	}
+}
+
+func TestEndpointInvalid(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+
+	_, err := newExporter(context.Background(), OtelGoMetricsConfig{Endpoint: "http://[::1"})
+	if err == nil || !strings.HasPrefix(err.Error(), "Endpoint: ") {
+		t.Errorf("newExporter error = %v, want an Endpoint error", err)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config OtelGoMetricsConfig
+		want   string
+	}{
+		{name: "default", want: ""},
+		{name: "config", config: OtelGoMetricsConfig{Compression: "gzip"}, want: "gzip"},
+		{name: "metrics environment", env: map[string]string{"OTEL_EXPORTER_OTLP_METRICS_COMPRESSION": "gzip"}, want: "gzip"},
+		{name: "generic environment", env: map[string]string{"OTEL_EXPORTER_OTLP_COMPRESSION": "gzip"}, want: "gzip"},
+		{name: "config over environment", env: map[string]string{"OTEL_EXPORTER_OTLP_METRICS_COMPRESSION": "gzip"}, config: OtelGoMetricsConfig{Compression: "none"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encodings := make(chan string, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encodings <- r.Header.Get("Content-Encoding")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			t.Setenv("OTEL_METRICS_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+			t.Setenv("OTEL_EXPORTER_OTLP_METRICS_COMPRESSION", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			config := tt.config
+			config.Endpoint = server.URL
+			if err := exportOneMetric(t, config); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := <-encodings; got != tt.want {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressionInvalid(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+
+	if _, err := newExporter(context.Background(), OtelGoMetricsConfig{Compression: "zstd"}); err == nil {
+		t.Error("newExporter accepted an unsupported compression, want an error")
+	}
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/host"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// startInstrumentation records the host and runtime metrics enabled by the configuration on the
+// meter provider, so they share its resource, readers and export interval.
+func startInstrumentation(config OtelGoMetricsConfig, meterProvider *sdk.MeterProvider) error {
+	if config.HostMetricsEnabled {
+		if err := host.Start(host.WithMeterProvider(meterProvider)); err != nil {
+			return err
+		}
+	}
+
+	if config.RuntimeMetricsEnabled {
+		opts := []runtime.Option{runtime.WithMeterProvider(meterProvider)}
+		if config.RuntimeMetricsInterval > 0 {
+			opts = append(opts, runtime.WithMinimumReadMemStatsInterval(config.RuntimeMetricsInterval))
+		}
+		if err := runtime.Start(opts...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInitRecordsHostAndRuntimeMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		deprecated string
+		config     OtelGoMetricsConfig
+		want       string
+	}{
+		{name: "runtime", deprecated: "false", config: OtelGoMetricsConfig{RuntimeMetricsEnabled: true}, want: "go.memory.used"},
+		{name: "deprecated runtime", config: OtelGoMetricsConfig{RuntimeMetricsEnabled: true}, want: "process.runtime.go.mem.heap_alloc"},
+		{name: "deprecated runtime with interval", config: OtelGoMetricsConfig{RuntimeMetricsEnabled: true, RuntimeMetricsInterval: time.Second}, want: "process.runtime.go.mem.heap_alloc"},
+		{name: "host", config: OtelGoMetricsConfig{HostMetricsEnabled: true}, want: "system.memory.usage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_GO_X_DEPRECATED_RUNTIME_METRICS", tt.deprecated)
+			reader, _ := initReader(t, tt.config)
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+
+			names := []string{}
+			for _, scope := range rm.ScopeMetrics {
+				for _, m := range scope.Metrics {
+					if m.Name == tt.want {
+						return
+					}
+					names = append(names, m.Name)
+				}
+			}
+			t.Errorf("instrument %s not collected, got %v", tt.want, names)
+		})
+	}
+}
+
+func TestInitRejectsNegativeRuntimeMetricsInterval(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	_, _, err := Init(context.Background(), OtelGoMetricsConfig{
+		DisableGlobal:          true,
+		RuntimeMetricsEnabled:  true,
+		RuntimeMetricsInterval: -time.Second,
+		Readers:                []sdk.Reader{sdk.NewManualReader()},
+	})
+	if err == nil {
+		t.Error("Init succeeded, want the negative RuntimeMetricsInterval rejected")
+	}
+}
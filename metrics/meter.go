@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// providerContextKey is the context key under which Init stores its meter provider.
+type providerContextKey struct{}
+
+// currentProvider holds the meter provider created by the most recent Init call.
+var currentProvider atomic.Pointer[sdk.MeterProvider]
+
+// Meter returns a meter from the provider created by Init, falling back to the global provider
+// when Init has not been called. The instrumentation scope version defaults to the otelgo module
+// version and can be overridden with metric.WithInstrumentationVersion.
+func Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	return meterFrom(meterProvider(), name, opts...)
+}
+
+// meterProvider returns the provider created by Init, or the global provider when Init has not
+// been called.
+func meterProvider() metric.MeterProvider {
+	if current := currentProvider.Load(); current != nil {
+		return current
+	}
+
+	return otel.GetMeterProvider()
+}
+
+// MeterFromContext returns a meter from the provider stored in ctx by Init, falling back to Meter
+// when ctx does not carry one.
+func MeterFromContext(ctx context.Context, name string, opts ...metric.MeterOption) metric.Meter {
+	if provider, ok := ctx.Value(providerContextKey{}).(*sdk.MeterProvider); ok {
+		return meterFrom(provider, name, opts...)
+	}
+
+	return Meter(name, opts...)
+}
+
+// meterFrom returns a meter from provider with the otelgo module version as default scope version.
+func meterFrom(provider metric.MeterProvider, name string, opts ...metric.MeterOption) metric.Meter {
+	if version := common.Version(); version != "" {
+		opts = append([]metric.MeterOption{metric.WithInstrumentationVersion(version)}, opts...)
+	}
+
+	return provider.Meter(name, opts...)
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collectScopes returns the instrumentation scopes of the metrics collected by reader.
+func collectScopes(t *testing.T, reader *sdk.ManualReader) []metricdata.ScopeMetrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	return rm.ScopeMetrics
+}
+
+func TestMeterScope(t *testing.T) {
+	// An unrelated global provider must not receive the instruments
+	globalReader := sdk.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdk.NewMeterProvider(sdk.WithReader(globalReader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+	reader := sdk.NewManualReader()
+	ctx, meterProvider, err := Init(context.Background(), OtelGoMetricsConfig{DisableGlobal: true, Readers: []sdk.Reader{reader}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background(), meterProvider) })
+
+	tests := []struct {
+		name        string
+		meter       metric.Meter
+		wantName    string
+		wantVersion string
+	}{
+		{name: "meter", meter: Meter("github.com/acme/orders"), wantName: "github.com/acme/orders", wantVersion: common.Version()},
+		{name: "meter from context", meter: MeterFromContext(ctx, "github.com/acme/payments"), wantName: "github.com/acme/payments", wantVersion: common.Version()},
+		{name: "meter from context without provider", meter: MeterFromContext(context.Background(), "github.com/acme/billing"), wantName: "github.com/acme/billing", wantVersion: common.Version()},
+		{name: "version override", meter: Meter("github.com/acme/stock", metric.WithInstrumentationVersion("v1.2.3")), wantName: "github.com/acme/stock", wantVersion: "v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, err := tt.meter.Int64Counter("requests.total")
+			if err != nil {
+				t.Fatal(err)
+			}
+			counter.Add(context.Background(), 1)
+
+			found := false
+			for _, sm := range collectScopes(t, reader) {
+				if sm.Scope.Name == tt.wantName {
+					found = true
+					if sm.Scope.Version != tt.wantVersion {
+						t.Errorf("scope = %s@%s, want %s@%s", sm.Scope.Name, sm.Scope.Version, tt.wantName, tt.wantVersion)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("scope %s was not collected from the otelgo provider", tt.wantName)
+			}
+		})
+	}
+
+	if scopes := collectScopes(t, globalReader); len(scopes) != 0 {
+		t.Errorf("global provider collected %d scopes, want none", len(scopes))
+	}
+}
@@ -2,82 +2,203 @@ package metrics
 
 import (
 	"context"
+	"crypto/tls"
+	"log/slog"
 	"os"
+	"time"
 
 	"dario.cat/mergo"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/wasilak/otelgo/common"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	sdk "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
-	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 // OtelGoMetricsConfig specifies the configuration for the OpenTelemetry metrics.
 type OtelGoMetricsConfig struct {
-	Attributes []attribute.KeyValue `json:"attributes"` // Attributes specifies the attributes to be added to the metric resource. Default is an empty slice.
+	Attributes               []attribute.KeyValue        `json:"attributes"`                 // Attributes specifies the attributes to be added to the metric resource. They extend the default service.name from OTEL_SERVICE_NAME, keys set here taking precedence. Default is an empty slice.
+	ReplaceDefaultAttributes bool                        `json:"replace_default_attributes"` // ReplaceDefaultAttributes specifies whether a non-empty Attributes replaces the default attributes instead of extending them. Default is false.
+	AttributeMap             map[string]string           `json:"attribute_map"`              // AttributeMap specifies additional string attributes to be added to the metric resource, combined with Attributes. Default is nil.
+	ServiceVersion           string                      `json:"service_version"`            // ServiceVersion specifies the service.version resource attribute, applied only when non-empty. Default is empty, leaving the version to OTEL_RESOURCE_ATTRIBUTES.
+	ServiceGroup             string                      `json:"service_group"`              // ServiceGroup specifies the service.group resource attribute naming the logical system the service belongs to. Default is empty, using OTEL_SERVICE_GROUP.
+	ResourceConfig           common.ResourceConfig       `json:"resource_config"`            // ResourceConfig specifies how the metric resource is detected. Default is all detectors enabled.
+	Resource                 *resource.Resource          `json:"-"`                          // Resource specifies a prebuilt resource used as is, e.g. one shared by all signals, in which case Attributes, AttributeMap, ServiceVersion, ServiceGroup and ResourceConfig are ignored. Default is nil, detecting the metric resource.
+	Disabled                 bool                        `json:"disabled"`                   // Disabled specifies whether the metrics are neither collected nor exported, apart from collection by Readers, the programmatic equivalent of OTEL_METRICS_EXPORTER=none or OTEL_EXPORTER_OTLP_METRICS_PROTOCOL=none. Default is false.
+	DisableGlobal            bool                        `json:"disable_global"`             // DisableGlobal specifies whether Init leaves the global meter provider untouched. Default is false, setting the global provider.
+	ExportResultCallback     common.ExportResultCallback `json:"-"`                          // ExportResultCallback specifies a function called after every export batch with its size and error. Default is nil.
+	ExportDebugLogger        *slog.Logger                `json:"-"`                          // ExportDebugLogger specifies a logger receiving a debug level summary of every export batch, e.g. while debugging the collector. Default is nil, logging nothing.
+	DefaultAttributes        []attribute.KeyValue        `json:"default_attributes"`         // DefaultAttributes specifies attributes added to every exported data point, for backends that do not turn resource attributes into labels. Default is an empty slice.
+	Temporality              string                      `json:"temporality"`                // Temporality specifies the aggregation temporality of the OTLP exporter, "cumulative", "delta" or "lowmemory", e.g. "delta" for backends that require it. Default is empty, using OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE or cumulative.
+	TemporalitySelector      sdk.TemporalitySelector     `json:"-"`                          // TemporalitySelector specifies a custom temporality per instrument kind for the OTLP exporter, overriding Temporality. Default is nil.
+	Endpoint                 string                      `json:"endpoint"`                   // Endpoint specifies the OTLP metrics endpoint, either host:port or a full URL such as https://collector:4318/v1/metrics. Default is empty, using OTEL_EXPORTER_OTLP_METRICS_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT.
+	EndpointURLPath          string                      `json:"endpoint_url_path"`          // EndpointURLPath specifies the URL path of the HTTP metrics exporter, e.g. a collector behind a reverse proxy. Default is empty, using /v1/metrics.
+	Headers                  map[string]string           `json:"headers"`                    // Headers specifies the headers sent with every metric export, e.g. authorization. Default is nil, using OTEL_EXPORTER_OTLP_METRICS_HEADERS or OTEL_EXPORTER_OTLP_HEADERS.
+	Compression              string                      `json:"compression"`                // Compression specifies the metric export compression, "none" or "gzip", e.g. for large payloads of high-cardinality services. Default is empty, using OTEL_EXPORTER_OTLP_METRICS_COMPRESSION or OTEL_EXPORTER_OTLP_COMPRESSION.
+	ExemplarFilter           string                      `json:"exemplar_filter"`            // ExemplarFilter specifies which measurements are recorded as exemplars, "always_off", "always_on" or "trace_based", e.g. to link histogram buckets to the traces of sampled spans. Default is empty, using OTEL_METRICS_EXEMPLAR_FILTER or trace_based.
+	ExportInterval           time.Duration               `json:"export_interval"`            // ExportInterval specifies the interval between metric exports. Default is 0, using OTEL_METRIC_EXPORT_INTERVAL or 60 seconds.
+	ExportTimeout            time.Duration               `json:"export_timeout"`             // ExportTimeout specifies the time limit of a single metric export. Default is 0, using OTEL_METRIC_EXPORT_TIMEOUT or 30 seconds.
+	AlignedReporting         bool                        `json:"aligned_reporting"`          // AlignedReporting specifies whether metrics are exported at multiples of the export interval on the wall clock, e.g. at :00 and :15 seconds, instead of relative to Init. Default is false.
+	ShareGRPCConn            bool                        `json:"share_grpc_conn"`            // ShareGRPCConn specifies whether the gRPC metric exporter shares one connection with the other otelgo exporters using the same endpoint and TLS settings, which follow OTEL_EXPORTER_OTLP_METRICS_INSECURE and the certificate variables unless set explicitly. Default is false, dialing a dedicated connection.
+	Views                    []sdk.View                  `json:"-"`                          // Views specifies views applied by the meter provider, e.g. to rename instruments, drop attributes or set histogram buckets, in addition to those from OTELGO_METRIC_VIEWS. Default is nil.
+	ConsoleExporter          bool                        `json:"console_exporter"`           // ConsoleExporter specifies whether metrics are written to stdout instead of OTLP, also enabled by OTEL_METRICS_EXPORTER=console. Default is false.
+	ConsolePrettyPrint       bool                        `json:"console_pretty_print"`       // ConsolePrettyPrint specifies whether the console exporter indents its JSON output. Default is false.
+	PrometheusExporter       bool                        `json:"prometheus_exporter"`        // PrometheusExporter specifies whether metrics are exposed for Prometheus scraping, see PrometheusHandler, instead of pushed with OTLP, also enabled by OTEL_METRICS_EXPORTER=prometheus. The exporter settings and wrappers do not apply. Default is false.
+	PrometheusRegistry       *prometheus.Registry        `json:"-"`                          // PrometheusRegistry specifies the registry the Prometheus exporter is registered on. Default is nil, using the default Prometheus registerer.
+	HostMetricsEnabled       bool                        `json:"host_metrics_enabled"`       // HostMetricsEnabled specifies whether host metrics, e.g. CPU and memory usage, are recorded on the meter provider and exported with the other metrics, without initializing tracing. Default is false.
+	RuntimeMetricsEnabled    bool                        `json:"runtime_metrics_enabled"`    // RuntimeMetricsEnabled specifies whether Go runtime metrics, e.g. goroutines and heap usage, are recorded on the meter provider and exported with the other metrics, without initializing tracing. Default is false.
+	RuntimeMetricsInterval   time.Duration               `json:"runtime_metrics_interval"`   // RuntimeMetricsInterval specifies the minimum interval between reads of the Go memory statistics by the process.runtime.go runtime metrics, recorded unless OTEL_GO_X_DEPRECATED_RUNTIME_METRICS=false, as these reads are relatively expensive. Host metrics have no such setting and are read at every collection. Default is 0, using 15 seconds.
+	Readers                  []sdk.Reader                `json:"-"`                          // Readers specifies additional readers registered on the meter provider, e.g. a sdk.ManualReader collecting metrics in tests. With Disabled or OTEL_METRICS_EXPORTER=none they are the only readers and no exporter is set up. Default is nil.
+	ExporterFactory          ExporterFactory             `json:"-"`                          // ExporterFactory specifies a function creating the metric exporter in place of the OTLP exporter. Default is nil, using OTLP.
 }
 
+// ExporterFactory creates the metric exporter used by Init, receiving the TLS configuration the
+// OTLP exporter would use, nil when it relies on the system defaults. The exporter is shut down
+// with the meter provider.
+type ExporterFactory func(ctx context.Context, tlsConfig *tls.Config) (sdk.Exporter, error)
+
 // defaultConfig specifies the default configuration for the OpenTelemetry metrics.
-var defaultConfig = OtelGoMetricsConfig{
-	Attributes: []attribute.KeyValue{
-		semconv.ServiceNameKey.String(os.Getenv("OTEL_SERVICE_NAME")),
-		semconv.ServiceVersionKey.String("v0.0.0"),
-	},
-}
+var defaultConfig = OtelGoMetricsConfig{}
 
 // Init initializes an OpenTelemetry metric provider with a specified configuration.
 func Init(ctx context.Context, config OtelGoMetricsConfig) (context.Context, *sdk.MeterProvider, error) {
-	err := mergo.Merge(&defaultConfig, config, mergo.WithOverride)
+	// The caller's config is merged into a copy of defaultConfig, so settings never leak between calls.
+	localConfig := defaultConfig
+	err := mergo.Merge(&localConfig, config, mergo.WithOverride)
 	if err != nil {
 		return ctx, nil, err
 	}
 
-	res, err := resource.New(ctx,
-		resource.WithHost(),
-		resource.WithContainer(),
-		resource.WithProcess(),
-		resource.WithTelemetrySDK(),
-		resource.WithOS(),
-		resource.WithFromEnv(),
-		resource.WithAttributes(defaultConfig.Attributes...),
-	)
-	if err != nil {
-		return ctx, nil, err
+	// Caller attributes extend the default service.name, read from OTEL_SERVICE_NAME now rather
+	// than at package init, their keys taking precedence, unless they are meant to replace it.
+	if !localConfig.ReplaceDefaultAttributes || len(localConfig.Attributes) == 0 {
+		localConfig.Attributes = common.MergeAttributes(common.ServiceNameAttributes(), localConfig.Attributes)
 	}
 
-	var exporter sdk.Exporter
+	// User attributes are de-duplicated up front so the last value set for a key
+	// always wins, regardless of how the resource detectors order them.
+	attributes := common.MergeAttributes(localConfig.Attributes, common.AttributesFromMap(localConfig.AttributeMap), common.ServiceVersionAttributes(localConfig.ServiceVersion), common.ServiceGroupAttributes(localConfig.ServiceGroup))
 
-	if common.IsOtlpProtocolGrpc("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL") {
-		exporter, err = otlpmetricgrpc.New(ctx)
+	// A prebuilt resource is used as is, skipping detection entirely.
+	res := localConfig.Resource
+	if res == nil {
+		res, err = common.NewResource(ctx, localConfig.ResourceConfig, attributes)
 		if err != nil {
 			return ctx, nil, err
 		}
-	} else {
-		exporter, err = otlpmetrichttp.New(ctx)
+	}
+
+	// A disabled signal gets a provider without reader, so no network setup is made, no background
+	// goroutine is started and Shutdown has nothing to flush.
+	disabled := localConfig.Disabled || common.IsSignalDisabled("OTEL_METRICS_EXPORTER", "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL")
+	if disabled && len(localConfig.Readers) == 0 {
+		meterProvider := sdk.NewMeterProvider(sdk.WithResource(res))
+		return register(ctx, localConfig, meterProvider), meterProvider, nil
+	}
+
+	if err := common.ValidateInterval("RuntimeMetricsInterval", localConfig.RuntimeMetricsInterval); err != nil {
+		return ctx, nil, err
+	}
+
+	views, err := viewsFromEnv()
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	views = append(views, localConfig.Views...)
+
+	filter, err := exemplarFilter(localConfig)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	opts := []sdk.Option{
+		sdk.WithResource(res),
+		sdk.WithView(views...),
+	}
+	if filter != nil {
+		opts = append(opts, sdk.WithExemplarFilter(filter))
+	}
+	for _, reader := range localConfig.Readers {
+		opts = append(opts, sdk.WithReader(reader))
+	}
+
+	// Prometheus scrapes metrics through its own pull reader, otherwise they are pushed by an exporter
+	if !disabled {
+		var reader sdk.Reader
+		if localConfig.PrometheusExporter || os.Getenv("OTEL_METRICS_EXPORTER") == "prometheus" {
+			reader, err = newPrometheusReader(localConfig)
+		} else {
+			reader, err = newPushReader(ctx, localConfig)
+		}
 		if err != nil {
 			return ctx, nil, err
 		}
+		opts = append(opts, sdk.WithReader(reader))
 	}
 
-	meterProvider := sdk.NewMeterProvider(
-		sdk.WithResource(res),
-		sdk.WithReader(sdk.NewPeriodicReader(exporter)),
-	)
+	// Every periodic reader runs its own collection and export goroutine, so readers registered
+	// on the provider already export in parallel and no extra concurrency option is needed.
+	meterProvider := sdk.NewMeterProvider(opts...)
 
-	otel.SetMeterProvider(meterProvider)
+	err = startInstrumentation(localConfig, meterProvider)
+	if err != nil {
+		_ = meterProvider.Shutdown(ctx)
+		return ctx, nil, err
+	}
 
-	return ctx, meterProvider, nil
+	return register(ctx, localConfig, meterProvider), meterProvider, nil
 }
 
-// Shutdown stops the metric provider.
+// register remembers meterProvider for Meter and MeterFromContext and sets it as the global
+// provider, unless the caller keeps its providers isolated. It returns ctx carrying the provider.
+func register(ctx context.Context, config OtelGoMetricsConfig, meterProvider *sdk.MeterProvider) context.Context {
+	currentProvider.Store(meterProvider)
+
+	if !config.DisableGlobal {
+		otel.SetMeterProvider(meterProvider)
+	}
+
+	return context.WithValue(ctx, providerContextKey{}, meterProvider)
+}
+
+// ForceFlush exports all metrics buffered by the meter provider without shutting it down, e.g.
+// before a serverless instance is frozen. The provider remains usable afterwards.
+func ForceFlush(ctx context.Context, meterProvider *sdk.MeterProvider) error {
+	return meterProvider.ForceFlush(ctx)
+}
+
+// Shutdown stops the metric provider. Only the first call for a provider does so, later calls
+// do nothing.
 func Shutdown(ctx context.Context, meterProvider *sdk.MeterProvider) {
 	defer func() {
-		err := meterProvider.Shutdown(ctx)
+		err := shutdown(ctx, meterProvider)
 		if err != nil {
 			panic(err)
 		}
 	}()
 }
+
+// shutdown stops the meter provider, which Meter no longer returns meters from. Only the first
+// call for a provider does so, later calls return nil.
+func shutdown(ctx context.Context, meterProvider *sdk.MeterProvider) error {
+	return common.ShutdownOnce(ctx, meterProvider, func(ctx context.Context) error {
+		currentProvider.CompareAndSwap(meterProvider, nil)
+
+		// The provider returns exactly ErrReaderShutdown, unwrapped, when it was already shut down.
+		if err := meterProvider.Shutdown(ctx); err != sdk.ErrReaderShutdown {
+			return err
+		}
+		return nil
+	})
+}
+
+// ShutdownWithTimeout shuts down the meter provider like Shutdown, but returns instead of
+// panicking and never blocks longer than timeout, even when the collector is unreachable.
+// A shutdown cut short by the timeout returns an error matching common.ErrShutdownTimeout.
+func ShutdownWithTimeout(ctx context.Context, meterProvider *sdk.MeterProvider, timeout time.Duration) error {
+	return common.ShutdownWithTimeout(ctx, timeout, func(ctx context.Context) error {
+		return shutdown(ctx, meterProvider)
+	})
+}
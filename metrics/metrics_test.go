@@ -0,0 +1,295 @@
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// initReader initializes metrics without exporting or touching the global provider and returns a
+// reader collecting from the meter provider.
+func initReader(t *testing.T, config OtelGoMetricsConfig) (*sdk.ManualReader, *sdk.MeterProvider) {
+	t.Helper()
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	reader := sdk.NewManualReader()
+	config.DisableGlobal = true
+	config.Readers = append(config.Readers, reader)
+
+	_, meterProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background(), meterProvider) })
+
+	return reader, meterProvider
+}
+
+// collectResource returns the resource of the metrics collected by reader.
+func collectResource(t *testing.T, reader *sdk.ManualReader) *resource.Resource {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	return rm.Resource
+}
+
+func TestInitMergesDefaultAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceName string
+		resourceEnv string
+		config      OtelGoMetricsConfig
+		want        map[attribute.Key]string
+	}{
+		{
+			name:        "name from OTEL_RESOURCE_ATTRIBUTES survives caller attributes",
+			resourceEnv: "service.name=orders",
+			config:      OtelGoMetricsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "prod")}},
+			want:        map[attribute.Key]string{"service.name": "orders", "env": "prod"},
+		},
+		{
+			name:        "name from OTEL_SERVICE_NAME survives caller attributes",
+			serviceName: "payments",
+			config:      OtelGoMetricsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "prod")}},
+			want:        map[attribute.Key]string{"service.name": "payments", "env": "prod"},
+		},
+		{
+			name:        "caller name takes precedence",
+			serviceName: "payments",
+			config:      OtelGoMetricsConfig{Attributes: []attribute.KeyValue{attribute.String("service.name", "billing")}},
+			want:        map[attribute.Key]string{"service.name": "billing"},
+		},
+		{
+			name:   "attribute map merges with attributes",
+			config: OtelGoMetricsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "prod")}, AttributeMap: map[string]string{"team": "payments", "region": "eu"}},
+			want:   map[attribute.Key]string{"env": "prod", "team": "payments", "region": "eu"},
+		},
+		{
+			name:   "last value of a repeated key wins",
+			config: OtelGoMetricsConfig{Attributes: []attribute.KeyValue{attribute.String("env", "dev"), attribute.String("team", "orders"), attribute.String("env", "prod")}},
+			want:   map[attribute.Key]string{"env": "prod", "team": "orders"},
+		},
+		{
+			name:   "service version",
+			config: OtelGoMetricsConfig{ServiceVersion: "1.2.3"},
+			want:   map[attribute.Key]string{"service.version": "1.2.3"},
+		},
+		{
+			name:        "version from OTEL_RESOURCE_ATTRIBUTES is kept",
+			resourceEnv: "service.version=2.0.0",
+			want:        map[attribute.Key]string{"service.version": "2.0.0"},
+		},
+		{
+			name: "no version is injected",
+			want: map[attribute.Key]string{"service.version": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_SERVICE_NAME", tt.serviceName)
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", tt.resourceEnv)
+
+			reader, _ := initReader(t, tt.config)
+			res := collectResource(t, reader)
+
+			for key, want := range tt.want {
+				if got, _ := res.Set().Value(key); got.AsString() != want {
+					t.Errorf("%s = %q, want %q", key, got.AsString(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestShutdownTwice(t *testing.T) {
+	_, meterProvider := initReader(t, OtelGoMetricsConfig{})
+
+	for i := 0; i < 2; i++ {
+		if err := ShutdownWithTimeout(context.Background(), meterProvider, time.Second); err != nil {
+			t.Fatalf("shutdown %d error = %v, want nil", i+1, err)
+		}
+	}
+}
+
+func TestInitDisableGlobal(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	previous := otel.GetMeterProvider()
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+	for _, disableGlobal := range []bool{true, false} {
+		sentinel := sdk.NewMeterProvider()
+		otel.SetMeterProvider(sentinel)
+
+		_, meterProvider, err := Init(context.Background(), OtelGoMetricsConfig{DisableGlobal: disableGlobal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = shutdown(context.Background(), meterProvider) }()
+
+		want := metric.MeterProvider(meterProvider)
+		if disableGlobal {
+			want = sentinel
+		}
+		if got := otel.GetMeterProvider(); got != want {
+			t.Errorf("DisableGlobal %t: global meter provider = %p, want %p", disableGlobal, got, want)
+		}
+	}
+}
+
+func TestShutdownWithTimeoutStalledCollector(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stalled:
+		}
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+
+	_, meterProvider, err := Init(context.Background(), OtelGoMetricsConfig{DisableGlobal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter, err := meterProvider.Meter("test").Int64Counter("requests.total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Add(context.Background(), 1)
+
+	start := time.Now()
+	err = ShutdownWithTimeout(context.Background(), meterProvider, 200*time.Millisecond)
+	if !errors.Is(err, common.ErrShutdownTimeout) {
+		t.Errorf("ShutdownWithTimeout() = %v, want ErrShutdownTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ShutdownWithTimeout returned after %s, want no later than the 200ms deadline", elapsed)
+	}
+}
+
+func TestInitResource(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+	res := resource.NewSchemaless(attribute.String("service.name", "orders"), attribute.String("team", "payments"))
+	reader, _ := initReader(t, OtelGoMetricsConfig{
+		Resource:   res,
+		Attributes: []attribute.KeyValue{attribute.String("ignored", "true")},
+	})
+
+	if got := collectResource(t, reader); !got.Equal(res) {
+		t.Errorf("metric resource = %v, want the injected %v", got.Attributes(), res.Attributes())
+	}
+}
+
+func TestInitServiceGroup(t *testing.T) {
+	tests := []struct {
+		name  string
+		group string
+		env   string
+		want  string
+	}{
+		{name: "config", group: "checkout", want: "checkout"},
+		{name: "env fallback", env: "billing", want: "billing"},
+		{name: "config wins over env", group: "checkout", env: "billing", want: "checkout"},
+		{name: "unset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_SERVICE_GROUP", tt.env)
+
+			reader, _ := initReader(t, OtelGoMetricsConfig{ServiceGroup: tt.group})
+			res := collectResource(t, reader)
+			if got, _ := res.Set().Value(common.ServiceGroupKey); got.AsString() != tt.want {
+				t.Errorf("metric resource service.group = %q, want %q", got.AsString(), tt.want)
+			}
+			if tt.want == "" && res.Set().HasValue(common.ServiceGroupKey) {
+				t.Errorf("metric resource has service.group without a group set")
+			}
+		})
+	}
+}
+
+func TestInitReaders(t *testing.T) {
+	tests := []struct {
+		name         string
+		exporterEnv  string
+		config       OtelGoMetricsConfig
+		wantExporter bool
+	}{
+		{name: "alongside the exporter", wantExporter: true},
+		{name: "disabled", config: OtelGoMetricsConfig{Disabled: true}},
+		{name: "disabled from env", exporterEnv: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_METRICS_EXPORTER", tt.exporterEnv)
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+			reader := sdk.NewManualReader()
+			exporterCreated := false
+			config := tt.config
+			config.Attributes = []attribute.KeyValue{attribute.String("team", "payments")}
+			config.Readers = []sdk.Reader{reader}
+			config.DisableGlobal = true
+			config.ExporterFactory = func(context.Context, *tls.Config) (sdk.Exporter, error) {
+				exporterCreated = true
+				return &memoryExporter{}, nil
+			}
+
+			_, meterProvider, err := Init(context.Background(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = shutdown(context.Background(), meterProvider) }()
+
+			if exporterCreated != tt.wantExporter {
+				t.Errorf("exporter created = %t, want %t", exporterCreated, tt.wantExporter)
+			}
+
+			counter, err := meterProvider.Meter("test").Int64Counter("requests.total")
+			if err != nil {
+				t.Fatal(err)
+			}
+			counter.Add(context.Background(), 1)
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+			if len(rm.ScopeMetrics) != 1 || rm.ScopeMetrics[0].Metrics[0].Name != "requests.total" {
+				t.Errorf("collected %v, want requests.total", rm.ScopeMetrics)
+			}
+			if got, _ := rm.Resource.Set().Value("team"); got.AsString() != "payments" {
+				t.Errorf("team = %q, want payments from Attributes", got.AsString())
+			}
+			if !rm.Resource.Set().HasValue("telemetry.sdk.name") {
+				t.Error("resource lacks the detected telemetry.sdk.name")
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// newPrometheusReader returns a reader collecting metrics when the registry is scraped, registered
+// on PrometheusRegistry or the default Prometheus registerer.
+func newPrometheusReader(config OtelGoMetricsConfig) (sdk.Reader, error) {
+	opts := []otelprometheus.Option{}
+	if config.PrometheusRegistry != nil {
+		opts = append(opts, otelprometheus.WithRegisterer(config.PrometheusRegistry))
+	}
+
+	return otelprometheus.New(opts...)
+}
+
+// PrometheusHandler returns the handler to expose on /metrics for the Prometheus exporter
+// registered on registry, or on the default Prometheus registerer when registry is nil.
+func PrometheusHandler(registry *prometheus.Registry) http.Handler {
+	if registry == nil {
+		return promhttp.Handler()
+	}
+
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
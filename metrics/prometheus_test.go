@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrometheusExporter(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		config OtelGoMetricsConfig
+	}{
+		{name: "config", config: OtelGoMetricsConfig{PrometheusExporter: true}},
+		{name: "env", env: "prometheus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_METRICS_EXPORTER", tt.env)
+
+			registry := prometheus.NewRegistry()
+			config := tt.config
+			config.PrometheusRegistry = registry
+			config.DisableGlobal = true
+
+			_, meterProvider, err := Init(context.Background(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = shutdown(context.Background(), meterProvider) }()
+
+			counter, err := meterProvider.Meter("test").Int64Counter("orders.placed")
+			if err != nil {
+				t.Fatal(err)
+			}
+			counter.Add(context.Background(), 3)
+
+			recorder := httptest.NewRecorder()
+			PrometheusHandler(registry).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+			body, err := io.ReadAll(recorder.Result().Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			found := false
+			for _, line := range strings.Split(string(body), "\n") {
+				if strings.HasPrefix(line, "orders_placed_total") && strings.HasSuffix(line, " 3") {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("scrape output does not contain orders_placed_total with the value 3:\n%s", body)
+			}
+		})
+	}
+}
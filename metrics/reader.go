@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/wasilak/otelgo/common"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// newPushReader creates the metric exporter, wrapped according to the configuration, and the
+// reader pushing to it periodically or at aligned boundaries.
+func newPushReader(ctx context.Context, config OtelGoMetricsConfig) (sdk.Reader, error) {
+	if err := common.ValidateInterval("ExportInterval", config.ExportInterval); err != nil {
+		return nil, err
+	}
+	if err := common.ValidateInterval("ExportTimeout", config.ExportTimeout); err != nil {
+		return nil, err
+	}
+
+	var err error
+	var exporter sdk.Exporter
+	if config.ExporterFactory != nil {
+		exporter, err = config.ExporterFactory(ctx, nil)
+	} else {
+		exporter, err = newExporter(ctx, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.DefaultAttributes) > 0 {
+		exporter = &attributesExporter{Exporter: exporter, attributes: config.DefaultAttributes}
+	}
+
+	if config.ExportResultCallback != nil {
+		exporter = &callbackExporter{Exporter: exporter, callback: config.ExportResultCallback}
+	}
+
+	if config.ExportDebugLogger != nil {
+		exporter = &debugExporter{Exporter: exporter, logger: config.ExportDebugLogger}
+	}
+
+	if config.AlignedReporting {
+		interval := config.ExportInterval
+		if interval == 0 {
+			interval, err = exportIntervalFromEnv()
+			if err != nil {
+				return nil, err
+			}
+		}
+		timeout := config.ExportTimeout
+		if timeout == 0 {
+			timeout = defaultExportTimeout
+		}
+		return newAlignedReader(exporter, interval, timeout), nil
+	}
+
+	// Unset values are left to the SDK, which reads OTEL_METRIC_EXPORT_INTERVAL and
+	// OTEL_METRIC_EXPORT_TIMEOUT.
+	readerOpts := []sdk.PeriodicReaderOption{}
+	if config.ExportInterval > 0 {
+		readerOpts = append(readerOpts, sdk.WithInterval(config.ExportInterval))
+	}
+	if config.ExportTimeout > 0 {
+		readerOpts = append(readerOpts, sdk.WithTimeout(config.ExportTimeout))
+	}
+
+	return sdk.NewPeriodicReader(exporter, readerOpts...), nil
+}
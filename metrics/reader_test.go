@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// deadlineExporter records the time left before the deadline of every export context.
+type deadlineExporter struct {
+	memoryExporter
+	remaining chan time.Duration
+}
+
+func (e *deadlineExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		select {
+		case e.remaining <- time.Until(deadline):
+		default:
+		}
+	}
+
+	return e.memoryExporter.Export(ctx, rm)
+}
+
+// initPeriodic initializes a provider pushing to exporter with config, without touching the
+// global provider.
+func initPeriodic(t *testing.T, config OtelGoMetricsConfig, exporter sdk.Exporter) {
+	t.Helper()
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+
+	config.DisableGlobal = true
+	config.ExporterFactory = func(context.Context, *tls.Config) (sdk.Exporter, error) {
+		return exporter, nil
+	}
+
+	_, meterProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background(), meterProvider) })
+}
+
+func TestExportInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		env      string
+		wantSoon bool
+	}{
+		{name: "config", interval: 50 * time.Millisecond, wantSoon: true},
+		{name: "env when unset", env: "50", wantSoon: true},
+		{name: "config wins over env", interval: time.Hour, env: "50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_METRIC_EXPORT_INTERVAL", tt.env)
+
+			exporter := &deadlineExporter{remaining: make(chan time.Duration, 1)}
+			initPeriodic(t, OtelGoMetricsConfig{ExportInterval: tt.interval}, exporter)
+
+			select {
+			case <-exporter.remaining:
+				if !tt.wantSoon {
+					t.Error("exported before the configured interval elapsed")
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.wantSoon {
+					t.Error("no export within 500ms of a 50ms interval")
+				}
+			}
+		})
+	}
+}
+
+func TestExportTimeout(t *testing.T) {
+	t.Setenv("OTEL_METRIC_EXPORT_TIMEOUT", "")
+
+	exporter := &deadlineExporter{remaining: make(chan time.Duration, 1)}
+	initPeriodic(t, OtelGoMetricsConfig{ExportInterval: 50 * time.Millisecond, ExportTimeout: 200 * time.Millisecond}, exporter)
+
+	select {
+	case remaining := <-exporter.remaining:
+		if remaining <= 0 || remaining > 200*time.Millisecond {
+			t.Errorf("export deadline in %s, want within the 200ms ExportTimeout", remaining)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no export within 2s")
+	}
+}
+
+func TestNewPushReaderAligned(t *testing.T) {
+	config := OtelGoMetricsConfig{
+		AlignedReporting: true,
+		ExportInterval:   15 * time.Second,
+		ExportTimeout:    2 * time.Second,
+		ExporterFactory: func(context.Context, *tls.Config) (sdk.Exporter, error) {
+			return &memoryExporter{}, nil
+		},
+	}
+
+	reader, err := newPushReader(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aligned := reader.(*alignedReader)
+	defer func() { _ = aligned.Shutdown(context.Background()) }()
+
+	if aligned.interval != config.ExportInterval || aligned.timeout != config.ExportTimeout {
+		t.Errorf("aligned reader interval = %s, timeout = %s, want %s and %s", aligned.interval, aligned.timeout, config.ExportInterval, config.ExportTimeout)
+	}
+}
+
+func TestInitRejectsNegativeExportInterval(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+
+	for name, config := range map[string]OtelGoMetricsConfig{
+		"ExportInterval": {ExportInterval: -time.Second},
+		"ExportTimeout":  {ExportTimeout: -time.Second},
+	} {
+		config.DisableGlobal = true
+		config.ExporterFactory = func(context.Context, *tls.Config) (sdk.Exporter, error) {
+			return &memoryExporter{}, nil
+		}
+
+		if _, _, err := Init(context.Background(), config); err == nil {
+			t.Errorf("Init with a negative %s succeeded, want an error", name)
+		}
+	}
+}
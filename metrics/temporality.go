@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Temporality preferences supported by OtelGoMetricsConfig.Temporality, with the meaning of
+// OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE.
+const (
+	TemporalityCumulative = "cumulative"
+	TemporalityDelta      = "delta"
+	TemporalityLowMemory  = "lowmemory"
+)
+
+// temporalitySelector returns the selector configured by TemporalitySelector or Temporality, or nil
+// when neither is set and the exporter keeps its default, which honours
+// OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE.
+func temporalitySelector(config OtelGoMetricsConfig) (sdk.TemporalitySelector, error) {
+	if config.TemporalitySelector != nil {
+		return config.TemporalitySelector, nil
+	}
+
+	switch strings.ToLower(config.Temporality) {
+	case "":
+		return nil, nil
+	case TemporalityCumulative:
+		return sdk.DefaultTemporalitySelector, nil
+	case TemporalityDelta:
+		return deltaTemporality, nil
+	case TemporalityLowMemory:
+		return lowMemoryTemporality, nil
+	default:
+		return nil, fmt.Errorf("Temporality: unsupported temporality %q, expected %s, %s or %s", config.Temporality, TemporalityCumulative, TemporalityDelta, TemporalityLowMemory)
+	}
+}
+
+// deltaTemporality reports counters and histograms as deltas, keeping up-down counters and gauges
+// cumulative as their deltas are meaningless.
+func deltaTemporality(kind sdk.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case sdk.InstrumentKindCounter, sdk.InstrumentKindObservableCounter, sdk.InstrumentKindHistogram:
+		return metricdata.DeltaTemporality
+	default:
+		return metricdata.CumulativeTemporality
+	}
+}
+
+// lowMemoryTemporality reports synchronous counters and histograms as deltas, so their state is
+// reset after every export, and everything else as cumulative.
+func lowMemoryTemporality(kind sdk.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case sdk.InstrumentKindCounter, sdk.InstrumentKindHistogram:
+		return metricdata.DeltaTemporality
+	default:
+		return metricdata.CumulativeTemporality
+	}
+}
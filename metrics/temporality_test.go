@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestTemporality(t *testing.T) {
+	kinds := []sdk.InstrumentKind{
+		sdk.InstrumentKindCounter,
+		sdk.InstrumentKindUpDownCounter,
+		sdk.InstrumentKindHistogram,
+		sdk.InstrumentKindObservableCounter,
+		sdk.InstrumentKindObservableUpDownCounter,
+		sdk.InstrumentKindObservableGauge,
+		sdk.InstrumentKindGauge,
+	}
+	cumulative := metricdata.CumulativeTemporality
+	delta := metricdata.DeltaTemporality
+
+	tests := []struct {
+		name   string
+		env    string
+		config OtelGoMetricsConfig
+		want   []metricdata.Temporality
+	}{
+		{
+			name: "default",
+			want: []metricdata.Temporality{cumulative, cumulative, cumulative, cumulative, cumulative, cumulative, cumulative},
+		},
+		{
+			name:   "delta",
+			config: OtelGoMetricsConfig{Temporality: TemporalityDelta},
+			want:   []metricdata.Temporality{delta, cumulative, delta, delta, cumulative, cumulative, cumulative},
+		},
+		{
+			name:   "lowmemory",
+			config: OtelGoMetricsConfig{Temporality: TemporalityLowMemory},
+			want:   []metricdata.Temporality{delta, cumulative, delta, cumulative, cumulative, cumulative, cumulative},
+		},
+		{
+			name: "delta from env",
+			env:  "delta",
+			want: []metricdata.Temporality{delta, cumulative, delta, delta, cumulative, cumulative, cumulative},
+		},
+		{
+			name:   "config wins over env",
+			env:    "delta",
+			config: OtelGoMetricsConfig{Temporality: TemporalityCumulative},
+			want:   []metricdata.Temporality{cumulative, cumulative, cumulative, cumulative, cumulative, cumulative, cumulative},
+		},
+		{
+			name: "selector wins over temporality",
+			config: OtelGoMetricsConfig{
+				Temporality:         TemporalityCumulative,
+				TemporalitySelector: func(sdk.InstrumentKind) metricdata.Temporality { return delta },
+			},
+			want: []metricdata.Temporality{delta, delta, delta, delta, delta, delta, delta},
+		},
+	}
+
+	for _, protocol := range []string{"grpc", "http/protobuf"} {
+		for _, tt := range tests {
+			t.Run(protocol+"/"+tt.name, func(t *testing.T) {
+				t.Setenv("OTEL_METRICS_EXPORTER", "")
+				t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", protocol)
+				t.Setenv("OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE", tt.env)
+
+				exporter, err := newExporter(context.Background(), tt.config)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer func() { _ = exporter.Shutdown(context.Background()) }()
+
+				for i, kind := range kinds {
+					if got := exporter.Temporality(kind); got != tt.want[i] {
+						t.Errorf("%s temporality = %s, want %s", kind, got, tt.want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTemporalityInvalid(t *testing.T) {
+	if _, err := temporalitySelector(OtelGoMetricsConfig{Temporality: "sometimes"}); err == nil {
+		t.Error("temporalitySelector accepted an unsupported temporality, want an error")
+	}
+}
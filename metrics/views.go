@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// viewsEnv is the environment variable holding view rules applied at Init.
+//
+// Rules are separated by semicolons, instrument names may use the `*` and `?` wildcards except in
+// rename rules:
+//
+//	drop:<instrument>                   drops the instrument
+//	rename:<instrument>=<name>          exports the instrument under a new name
+//	buckets:<instrument>=<b1>,<b2>,...  uses explicit histogram bucket boundaries
+//
+// Example: OTELGO_METRIC_VIEWS="drop:http.server.*;buckets:db.duration=0.1,0.5,1"
+const viewsEnv = "OTELGO_METRIC_VIEWS"
+
+// viewsFromEnv parses the view rules from OTELGO_METRIC_VIEWS.
+func viewsFromEnv() ([]sdk.View, error) {
+	return parseViews(os.Getenv(viewsEnv))
+}
+
+// parseViews parses view rules using the OTELGO_METRIC_VIEWS syntax.
+func parseViews(spec string) ([]sdk.View, error) {
+	views := []sdk.View{}
+
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		kind, body, ok := strings.Cut(rule, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid rule %q", viewsEnv, rule)
+		}
+
+		// An empty name would give a view matching no instrument
+		name, _, _ := strings.Cut(body, "=")
+		if name == "" {
+			return nil, fmt.Errorf("%s: missing instrument name in %q", viewsEnv, rule)
+		}
+
+		switch kind {
+		case "drop":
+			views = append(views, sdk.NewView(
+				sdk.Instrument{Name: body},
+				sdk.Stream{Aggregation: sdk.AggregationDrop{}},
+			))
+		case "rename":
+			name, newName, ok := strings.Cut(body, "=")
+			if !ok || newName == "" {
+				return nil, fmt.Errorf("%s: invalid rename rule %q", viewsEnv, rule)
+			}
+			// Renaming several instruments to one name would make their streams conflict
+			if strings.ContainsAny(name, "*?") {
+				return nil, fmt.Errorf("%s: rename rule %q uses a wildcard", viewsEnv, rule)
+			}
+			views = append(views, sdk.NewView(
+				sdk.Instrument{Name: name},
+				sdk.Stream{Name: newName},
+			))
+		case "buckets":
+			name, list, ok := strings.Cut(body, "=")
+			if !ok || list == "" {
+				return nil, fmt.Errorf("%s: invalid buckets rule %q", viewsEnv, rule)
+			}
+			boundaries := []float64{}
+			for _, b := range strings.Split(list, ",") {
+				boundary, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid bucket boundary in %q: %w", viewsEnv, rule, err)
+				}
+				boundaries = append(boundaries, boundary)
+			}
+			views = append(views, sdk.NewView(
+				sdk.Instrument{Name: name},
+				sdk.Stream{Aggregation: sdk.AggregationExplicitBucketHistogram{Boundaries: boundaries}},
+			))
+		default:
+			return nil, fmt.Errorf("%s: unknown rule type %q", viewsEnv, kind)
+		}
+	}
+
+	return views, nil
+}
@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestParseViews(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		views     int
+		probe     string
+		wantMatch bool
+		want      sdk.Stream
+		wantErr   bool
+	}{
+		{name: "empty", spec: " ; ", probe: "requests"},
+		{name: "drop", spec: "drop:http.*", views: 1, probe: "http.requests", wantMatch: true, want: sdk.Stream{Name: "http.requests", Aggregation: sdk.AggregationDrop{}}},
+		{name: "drop other instrument", spec: "drop:http.*", views: 1, probe: "db.queries"},
+		{name: "rename", spec: "rename:requests=http.requests", views: 1, probe: "requests", wantMatch: true, want: sdk.Stream{Name: "http.requests"}},
+		{name: "buckets", spec: "buckets:latency=0.1, 1,10", views: 1, probe: "latency", wantMatch: true, want: sdk.Stream{Name: "latency", Aggregation: sdk.AggregationExplicitBucketHistogram{Boundaries: []float64{0.1, 1, 10}}}},
+		{name: "several rules", spec: "drop:a;rename:b=c;", views: 2, probe: "a", wantMatch: true, want: sdk.Stream{Name: "a", Aggregation: sdk.AggregationDrop{}}},
+		{name: "missing type", spec: "requests", wantErr: true},
+		{name: "unknown type", spec: "sum:requests", wantErr: true},
+		{name: "rename without name", spec: "rename:requests=", wantErr: true},
+		{name: "rename wildcard", spec: "rename:http.*=requests", wantErr: true},
+		{name: "rename single character wildcard", spec: "rename:request?=requests", wantErr: true},
+		{name: "drop without name", spec: "drop:", wantErr: true},
+		{name: "rename without instrument", spec: "rename:=requests", wantErr: true},
+		{name: "buckets without instrument", spec: "buckets:=0.1,1", wantErr: true},
+		{name: "buckets without boundaries", spec: "buckets:latency", wantErr: true},
+		{name: "invalid boundary", spec: "buckets:latency=0.1,x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			views, err := parseViews(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("parseViews() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(views) != tt.views {
+				t.Fatalf("parseViews() returned %d views, want %d", len(views), tt.views)
+			}
+			if len(views) == 0 {
+				return
+			}
+
+			got, match := views[0](sdk.Instrument{Name: tt.probe})
+			if match != tt.wantMatch {
+				t.Fatalf("view matches %q = %t, want %t", tt.probe, match, tt.wantMatch)
+			}
+			if match && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("view stream = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitAppliesViewsFromEnv(t *testing.T) {
+	t.Setenv(viewsEnv, "drop:dropped.*")
+	reader, meterProvider := initReader(t, OtelGoMetricsConfig{})
+
+	meter := meterProvider.Meter("test")
+	for _, name := range []string{"dropped.total", "kept.total"} {
+		counter, err := meter.Int64Counter(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counter.Add(context.Background(), 1)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names = append(names, m.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"kept.total"}) {
+		t.Errorf("collected metrics %v, want only kept.total", names)
+	}
+}
+
+func TestInitAppliesConfigViews(t *testing.T) {
+	t.Setenv(viewsEnv, "")
+	buckets := []float64{0.1, 0.5, 1}
+	reader, meterProvider := initReader(t, OtelGoMetricsConfig{
+		Views: []sdk.View{
+			sdk.NewView(
+				sdk.Instrument{Name: "request.duration"},
+				sdk.Stream{
+					Name:        "http.server.duration",
+					Aggregation: sdk.AggregationExplicitBucketHistogram{Boundaries: buckets},
+				},
+			),
+		},
+	})
+
+	histogram, err := meterProvider.Meter("test").Float64Histogram("request.duration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	histogram.Record(context.Background(), 0.3)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("collected %v, want one metric", rm.ScopeMetrics)
+	}
+
+	m := rm.ScopeMetrics[0].Metrics[0]
+	if m.Name != "http.server.duration" {
+		t.Errorf("metric name = %s, want http.server.duration", m.Name)
+	}
+	points := m.Data.(metricdata.Histogram[float64]).DataPoints
+	if len(points) != 1 {
+		t.Fatalf("got %d data points, want 1", len(points))
+	}
+	if !reflect.DeepEqual(points[0].Bounds, buckets) {
+		t.Errorf("bucket bounds = %v, want %v", points[0].Bounds, buckets)
+	}
+	if want := []uint64{0, 1, 0, 0}; !reflect.DeepEqual(points[0].BucketCounts, want) {
+		t.Errorf("bucket counts = %v, want %v", points[0].BucketCounts, want)
+	}
+}
+
+func TestAttributelessCounterValues(t *testing.T) {
+	reader, meterProvider := initReader(t, OtelGoMetricsConfig{})
+
+	counter, err := meterProvider.Meter("test").Int64Counter("requests.total")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counter.Add(ctx, 2)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	points := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints
+	if len(points) != 1 || points[0].Value != 16000 || points[0].Attributes.Len() != 0 {
+		t.Errorf("requests.total = %+v, want a single point of 16000 without attributes", points)
+	}
+}
+
+// BenchmarkCounterAdd compares adding to a counter without attributes, which the SDK records on
+// its empty attribute set without allocating, to adding with attributes.
+func BenchmarkCounterAdd(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts []metric.AddOption
+	}{
+		{name: "without_attributes"},
+		{name: "with_attributes", opts: []metric.AddOption{metric.WithAttributes(attribute.String("route", "/a"))}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			provider := sdk.NewMeterProvider(sdk.WithReader(sdk.NewManualReader()))
+			defer func() { _ = provider.Shutdown(context.Background()) }()
+
+			counter, err := provider.Meter("bench").Int64Counter("bench")
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				counter.Add(ctx, 1, bm.opts...)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package otelgo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"dario.cat/mergo"
+	"github.com/wasilak/otelgo/common"
+	"github.com/wasilak/otelgo/logs"
+	"github.com/wasilak/otelgo/metrics"
+	"github.com/wasilak/otelgo/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Config specifies the configuration shared by logs, metrics and tracing, along with
+// the per-signal configurations. Shared values are applied to every signal, while
+// values set on a per-signal configuration take precedence.
+type Config struct {
+	Attributes     []attribute.KeyValue        `json:"attributes"`      // Attributes specifies the attributes to be added to every signal's resource. Default is an empty slice.
+	AttributeMap   map[string]string           `json:"attribute_map"`   // AttributeMap specifies additional string attributes to be added to every signal's resource. Default is nil.
+	ServiceVersion string                      `json:"service_version"` // ServiceVersion specifies the service.version resource attribute for every signal, unless a signal sets its own. Default is empty.
+	ServiceGroup   string                      `json:"service_group"`   // ServiceGroup specifies the service.group resource attribute for every signal, unless a signal sets its own. Default is empty, using OTEL_SERVICE_GROUP.
+	ResourceConfig common.ResourceConfig       `json:"resource_config"` // ResourceConfig specifies how the resource is detected for every signal, unless a signal sets its own. Default is all detectors enabled.
+	SharedResource bool                        `json:"shared_resource"` // SharedResource specifies whether one canonical resource is detected from the shared values and used by every signal, ignoring the per-signal attributes, service version, service group and resource config. Default is false, detecting a resource per signal.
+	Logs           logs.OtelGoLogsConfig       `json:"logs"`            // Logs specifies the logs configuration overrides.
+	Metrics        metrics.OtelGoMetricsConfig `json:"metrics"`         // Metrics specifies the metrics configuration overrides.
+	Tracing        tracing.Config              `json:"tracing"`         // Tracing specifies the tracing configuration overrides.
+}
+
+// Providers holds the providers created by Init.
+type Providers struct {
+	LoggerProvider *sdklog.LoggerProvider
+	MeterProvider  *sdkmetric.MeterProvider
+	TracerProvider *sdktrace.TracerProvider
+}
+
+// Init initializes OpenTelemetry logs, metrics and tracing with the shared configuration
+// applied to each of them.
+func Init(ctx context.Context, config Config) (context.Context, *Providers, error) {
+	providers := &Providers{}
+
+	// The canonical resource is detected once, so all signals report identical attributes and
+	// can be correlated. Sources are applied in increasing priority: detectors,
+	// OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME, Attributes, AttributeMap, ServiceVersion and
+	// ServiceGroup.
+	// A resource set on a signal configuration is still used for that signal.
+	if config.SharedResource {
+		attributes := common.MergeAttributes(config.Attributes, common.AttributesFromMap(config.AttributeMap), common.ServiceVersionAttributes(config.ServiceVersion), common.ServiceGroupAttributes(config.ServiceGroup))
+		res, err := common.NewResource(ctx, config.ResourceConfig, attributes)
+		if err != nil {
+			return ctx, nil, err
+		}
+
+		if config.Tracing.Resource == nil {
+			config.Tracing.Resource = res
+		}
+		if config.Metrics.Resource == nil {
+			config.Metrics.Resource = res
+		}
+		if config.Logs.Resource == nil {
+			config.Logs.Resource = res
+		}
+	}
+
+	tracingConfig := config.Tracing
+	tracingConfig.Attributes = common.MergeAttributes(config.Attributes, config.Tracing.Attributes)
+	tracingConfig.AttributeMap = mergeAttributeMaps(config.AttributeMap, config.Tracing.AttributeMap)
+	if tracingConfig.ServiceVersion == "" {
+		tracingConfig.ServiceVersion = config.ServiceVersion
+	}
+	if tracingConfig.ServiceGroup == "" {
+		tracingConfig.ServiceGroup = config.ServiceGroup
+	}
+	err := mergo.Merge(&tracingConfig.ResourceConfig, config.ResourceConfig)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	ctx, providers.TracerProvider, err = tracing.Init(ctx, tracingConfig)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	metricsConfig := config.Metrics
+	metricsConfig.Attributes = common.MergeAttributes(config.Attributes, config.Metrics.Attributes)
+	metricsConfig.AttributeMap = mergeAttributeMaps(config.AttributeMap, config.Metrics.AttributeMap)
+	if metricsConfig.ServiceVersion == "" {
+		metricsConfig.ServiceVersion = config.ServiceVersion
+	}
+	if metricsConfig.ServiceGroup == "" {
+		metricsConfig.ServiceGroup = config.ServiceGroup
+	}
+	err = mergo.Merge(&metricsConfig.ResourceConfig, config.ResourceConfig)
+	if err != nil {
+		return ctx, nil, shutdownStarted(ctx, providers, err)
+	}
+
+	ctx, providers.MeterProvider, err = metrics.Init(ctx, metricsConfig)
+	if err != nil {
+		return ctx, nil, shutdownStarted(ctx, providers, err)
+	}
+
+	logsConfig := config.Logs
+	logsConfig.Attributes = common.MergeAttributes(config.Attributes, config.Logs.Attributes)
+	logsConfig.AttributeMap = mergeAttributeMaps(config.AttributeMap, config.Logs.AttributeMap)
+	if logsConfig.ServiceVersion == "" {
+		logsConfig.ServiceVersion = config.ServiceVersion
+	}
+	if logsConfig.ServiceGroup == "" {
+		logsConfig.ServiceGroup = config.ServiceGroup
+	}
+	err = mergo.Merge(&logsConfig.ResourceConfig, config.ResourceConfig)
+	if err != nil {
+		return ctx, nil, shutdownStarted(ctx, providers, err)
+	}
+
+	ctx, providers.LoggerProvider, err = logs.Init(ctx, logsConfig)
+	if err != nil {
+		return ctx, nil, shutdownStarted(ctx, providers, err)
+	}
+
+	return ctx, providers, nil
+}
+
+// Shutdown closes all providers created by Init.
+func Shutdown(ctx context.Context, providers *Providers) {
+	logs.Shutdown(ctx, providers.LoggerProvider)
+	metrics.Shutdown(ctx, providers.MeterProvider)
+	tracing.Shutdown(ctx, providers.TracerProvider)
+}
+
+// initFailureShutdownTimeout bounds the shutdown of the providers already created when Init fails.
+const initFailureShutdownTimeout = 5 * time.Second
+
+// shutdownStarted stops the providers created before a later signal failed to initialize, so
+// none is left running with its exporters, readers and watchers. Any global provider they were
+// set as becomes a no-op. Shutdown errors are joined to err.
+func shutdownStarted(ctx context.Context, providers *Providers, err error) error {
+	errs := []error{err}
+	if providers.MeterProvider != nil {
+		errs = append(errs, metrics.ShutdownWithTimeout(ctx, providers.MeterProvider, initFailureShutdownTimeout))
+	}
+	if providers.TracerProvider != nil {
+		errs = append(errs, tracing.ShutdownWithTimeout(ctx, providers.TracerProvider, initFailureShutdownTimeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+// mergeAttributeMaps returns a new map holding the shared entries overridden by the
+// per-signal ones, leaving both inputs untouched.
+func mergeAttributeMaps(shared, signal map[string]string) map[string]string {
+	merged := make(map[string]string, len(shared)+len(signal))
+	for k, v := range shared {
+		merged[k] = v
+	}
+	for k, v := range signal {
+		merged[k] = v
+	}
+
+	return merged
+}
@@ -0,0 +1,158 @@
+package otelgo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wasilak/otelgo/logs"
+	"github.com/wasilak/otelgo/metrics"
+	"github.com/wasilak/otelgo/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// shutdownProcessor records whether the tracer provider shut it down.
+type shutdownProcessor struct {
+	shutdown atomic.Bool
+}
+
+func (p *shutdownProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *shutdownProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+func (p *shutdownProcessor) Shutdown(context.Context) error {
+	p.shutdown.Store(true)
+	return nil
+}
+
+func (p *shutdownProcessor) ForceFlush(context.Context) error { return nil }
+
+func TestInitShutsDownStartedProvidersOnFailure(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+	processor := &shutdownProcessor{}
+	reader := sdkmetric.NewManualReader()
+	errFactory := errors.New("log exporter unavailable")
+
+	_, providers, err := Init(context.Background(), Config{
+		Tracing: tracing.Config{DisableGlobal: true, SpanProcessors: []sdktrace.SpanProcessor{processor}},
+		Metrics: metrics.OtelGoMetricsConfig{DisableGlobal: true, Readers: []sdkmetric.Reader{reader}},
+		Logs: logs.OtelGoLogsConfig{DisableGlobal: true, ExporterFactory: func(context.Context, *tls.Config) (sdklog.Exporter, error) {
+			return nil, errFactory
+		}},
+	})
+	if !errors.Is(err, errFactory) {
+		t.Fatalf("Init error = %v, want %v", err, errFactory)
+	}
+	if providers != nil {
+		t.Errorf("Init returned providers %+v along with an error", providers)
+	}
+
+	if !processor.shutdown.Load() {
+		t.Error("tracer provider was not shut down")
+	}
+	if err := reader.Collect(context.Background(), &metricdata.ResourceMetrics{}); !errors.Is(err, sdkmetric.ErrReaderShutdown) {
+		t.Errorf("meter provider reader Collect error = %v, want %v", err, sdkmetric.ErrReaderShutdown)
+	}
+}
+
+// logExporter keeps the resource of the last exported log record.
+type logExporter struct {
+	mu       sync.Mutex
+	resource resource.Resource
+}
+
+func (e *logExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, record := range records {
+		e.resource = record.Resource()
+	}
+	return nil
+}
+
+func (e *logExporter) ForceFlush(context.Context) error { return nil }
+
+func (e *logExporter) Shutdown(context.Context) error { return nil }
+
+func TestInitSharedResource(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	exporter := &logExporter{}
+
+	ctx, providers, err := Init(context.Background(), Config{
+		SharedResource: true,
+		Attributes:     []attribute.KeyValue{attribute.String("service.name", "orders")},
+		ServiceVersion: "1.4.0",
+		Tracing: tracing.Config{
+			DisableGlobal:  true,
+			SpanProcessors: []sdktrace.SpanProcessor{recorder},
+			Attributes:     []attribute.KeyValue{attribute.String("signal", "traces")},
+		},
+		Metrics: metrics.OtelGoMetricsConfig{
+			DisableGlobal: true,
+			Readers:       []sdkmetric.Reader{reader},
+			Attributes:    []attribute.KeyValue{attribute.String("signal", "metrics")},
+		},
+		Logs: logs.OtelGoLogsConfig{
+			DisableGlobal: true,
+			ExporterFactory: func(context.Context, *tls.Config) (sdklog.Exporter, error) {
+				return exporter, nil
+			},
+			Attributes: []attribute.KeyValue{attribute.String("signal", "logs")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(context.Background(), providers)
+
+	_, span := providers.TracerProvider.Tracer("test").Start(ctx, "operation")
+	span.End()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("hello"))
+	providers.LoggerProvider.Logger("test").Emit(ctx, record)
+	if err := providers.LoggerProvider.ForceFlush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	encoder := attribute.DefaultEncoder()
+	traces := recorder.Ended()[0].Resource().Encoded(encoder)
+	exporter.mu.Lock()
+	logged := exporter.resource.Encoded(encoder)
+	exporter.mu.Unlock()
+	measured := rm.Resource.Encoded(encoder)
+
+	if traces != measured || traces != logged {
+		t.Errorf("resources differ:\ntraces:  %s\nmetrics: %s\nlogs:    %s", traces, measured, logged)
+	}
+	if !strings.Contains(traces, "service.name=orders") || !strings.Contains(traces, "service.version=1.4.0") {
+		t.Errorf("shared resource = %s, want the shared service name and version", traces)
+	}
+	if strings.Contains(traces, "signal=") {
+		t.Errorf("shared resource = %s, want the per-signal attributes ignored", traces)
+	}
+}
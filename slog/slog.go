@@ -15,7 +15,9 @@ import (
 // The TracingHandler type is a wrapper around a slog.Handler.
 // @property handler - The `handler` property is a variable of type `slog.Handler`.
 type TracingHandler struct {
-	handler slog.Handler
+	handler         slog.Handler
+	severityMapping map[slog.Level]otellog.Severity
+	flatten         bool
 }
 
 const sevOffset = slog.Level(otellog.SeverityDebug) - slog.LevelDebug
@@ -26,7 +28,32 @@ func NewTracingHandler(h slog.Handler) *TracingHandler {
 	if lh, ok := h.(*TracingHandler); ok {
 		h = lh.Handler()
 	}
-	return &TracingHandler{h}
+	return &TracingHandler{handler: h}
+}
+
+// WithSeverityMapping returns a copy of h that reports SeverityNumber using the given table
+// instead of the default OTEL mapping of slog levels. Levels missing from the table keep the
+// default mapping.
+func (h *TracingHandler) WithSeverityMapping(mapping map[slog.Level]otellog.Severity) *TracingHandler {
+	c := *h
+	c.severityMapping = mapping
+	return &c
+}
+
+// WithFlattenAttributes returns a copy of h that emits the attributes of grouped records as
+// dotted top-level keys, e.g. InstrumentationScope.Name, for backends unable to index nested
+// groups. Groups opened with WithGroup belong to the wrapped handler and are not flattened.
+func (h *TracingHandler) WithFlattenAttributes() *TracingHandler {
+	c := *h
+	c.flatten = true
+	return &c
+}
+
+// wrap returns a TracingHandler around handler carrying over the options of h.
+func (h *TracingHandler) wrap(handler slog.Handler) *TracingHandler {
+	c := *h
+	c.handler = handler
+	return &c
 }
 
 // Handler returns the Handler wrapped by h.
@@ -50,7 +77,11 @@ func (h *TracingHandler) Handle(ctx context.Context, r slog.Record) error {
 			span.SetStatus(codes.Error, r.Message)
 		}
 
-		r = alignWithOTELSpecs(r, span)
+		r = alignWithOTELSpecs(r, span, h.severityMapping)
+	}
+
+	if h.flatten {
+		r = flattenRecord(r)
 	}
 
 	return h.handler.Handle(ctx, r)
@@ -60,14 +91,50 @@ func (h *TracingHandler) Handle(ctx context.Context, r slog.Record) error {
 // defined on the `TracingHandler` struct. It takes a parameter `attrs` of type `[]slog.Attr`, which
 // represents a list of log attributes.
 func (h *TracingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return NewTracingHandler(h.handler.WithAttrs(attrs))
+	return h.wrap(h.handler.WithAttrs(attrs))
 }
 
 // The `func (h *TracingHandler) WithGroup(name string) slog.Handler {` method is defining a function
 // on the `TracingHandler` struct. This function takes a parameter `name` of type `string`, which
 // represents the name of the log group.
 func (h *TracingHandler) WithGroup(name string) slog.Handler {
-	return NewTracingHandler(h.handler.WithGroup(name))
+	return h.wrap(h.handler.WithGroup(name))
+}
+
+// flattenRecord returns a copy of r whose group attributes are replaced by their members, keyed
+// by the dotted path of group names.
+func flattenRecord(r slog.Record) slog.Record {
+	flat := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		flat.AddAttrs(flattenAttr("", attr)...)
+		return true
+	})
+
+	return flat
+}
+
+// flattenAttr returns attr, or the members of a group attr recursively, with keys prefixed by prefix.
+func flattenAttr(prefix string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+
+	key := attr.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		// Groups with an empty key are inlined, as slog handlers do.
+		key = prefix
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		return []slog.Attr{{Key: key, Value: attr.Value}}
+	}
+
+	attrs := []slog.Attr{}
+	for _, member := range attr.Value.Group() {
+		attrs = append(attrs, flattenAttr(key, member)...)
+	}
+
+	return attrs
 }
 
 // https://opentelemetry.io/docs/specs/otel/logs/data-model/#log-and-event-record-definition
@@ -82,7 +149,7 @@ func (h *TracingHandler) WithGroup(name string) slog.Handler {
 // Resource	Describes the source of the log.
 // InstrumentationScope	Describes the scope that emitted the log.
 // Attributes	Additional information about the event.
-func alignWithOTELSpecs(r slog.Record, span trace.Span) slog.Record {
+func alignWithOTELSpecs(r slog.Record, span trace.Span, severityMapping map[slog.Level]otellog.Severity) slog.Record {
 	traceId := ""
 	spanId := ""
 	traceFlags := ""
@@ -132,6 +199,9 @@ func alignWithOTELSpecs(r slog.Record, span trace.Span) slog.Record {
 	}
 
 	sev := slog.Level(int(r.Level)) + sevOffset
+	if mapped, ok := severityMapping[r.Level]; ok {
+		sev = slog.Level(mapped)
+	}
 
 	// Add severity and message details
 	r.AddAttrs(
@@ -0,0 +1,195 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// recordingProcessor keeps every log record emitted to the logger provider.
+type recordingProcessor struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (p *recordingProcessor) OnEmit(_ context.Context, record *sdklog.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.records = append(p.records, record.Clone())
+	return nil
+}
+
+func (p *recordingProcessor) Shutdown(context.Context) error { return nil }
+
+func (p *recordingProcessor) ForceFlush(context.Context) error { return nil }
+
+// last returns the attributes of the last recorded record by key.
+func (p *recordingProcessor) last(t *testing.T) map[string]otellog.Value {
+	t.Helper()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.records) == 0 {
+		t.Fatal("no log record was emitted")
+	}
+
+	attrs := map[string]otellog.Value{}
+	p.records[len(p.records)-1].WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+
+	return attrs
+}
+
+// bridgeHandler emits slog records to an OpenTelemetry logger, keeping slog groups as maps.
+type bridgeHandler struct {
+	logger otellog.Logger
+}
+
+func (h bridgeHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h bridgeHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := otellog.Record{}
+	record.SetTimestamp(r.Time)
+	record.SetBody(otellog.StringValue(r.Message))
+	r.Attrs(func(attr slog.Attr) bool {
+		record.AddAttributes(otellog.KeyValue{Key: attr.Key, Value: bridgeValue(attr.Value)})
+		return true
+	})
+	h.logger.Emit(ctx, record)
+
+	return nil
+}
+
+func (h bridgeHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h bridgeHandler) WithGroup(string) slog.Handler { return h }
+
+// bridgeValue converts a slog value to a log value.
+func bridgeValue(value slog.Value) otellog.Value {
+	value = value.Resolve()
+	switch value.Kind() {
+	case slog.KindInt64:
+		return otellog.Int64Value(value.Int64())
+	case slog.KindGroup:
+		members := []otellog.KeyValue{}
+		for _, attr := range value.Group() {
+			members = append(members, otellog.KeyValue{Key: attr.Key, Value: bridgeValue(attr.Value)})
+		}
+		return otellog.MapValue(members...)
+	default:
+		return otellog.StringValue(value.String())
+	}
+}
+
+// newTestLogger returns a logger writing through a TracingHandler configured by options to a
+// recording logger provider, and a context carrying a recording span.
+func newTestLogger(t *testing.T, options func(*TracingHandler) *TracingHandler) (context.Context, *slog.Logger, *recordingProcessor) {
+	t.Helper()
+
+	recorder := &recordingProcessor{}
+	logProvider := sdklog.NewLoggerProvider(sdklog.WithProcessor(recorder))
+	traceProvider := sdktrace.NewTracerProvider()
+	t.Cleanup(func() {
+		_ = logProvider.Shutdown(context.Background())
+		_ = traceProvider.Shutdown(context.Background())
+	})
+
+	handler := NewTracingHandler(bridgeHandler{logger: logProvider.Logger("test")})
+	if options != nil {
+		handler = options(handler)
+	}
+
+	ctx, span := traceProvider.Tracer("test").Start(context.Background(), "operation")
+	t.Cleanup(func() { span.End() })
+
+	return ctx, slog.New(handler), recorder
+}
+
+func TestWithSeverityMapping(t *testing.T) {
+	custom := map[slog.Level]otellog.Severity{
+		slog.LevelInfo:  otellog.SeverityInfo4,
+		slog.LevelError: otellog.SeverityFatal,
+	}
+
+	tests := []struct {
+		name    string
+		mapping map[slog.Level]otellog.Severity
+		level   slog.Level
+		want    otellog.Severity
+	}{
+		{name: "default debug", level: slog.LevelDebug, want: otellog.SeverityDebug},
+		{name: "default info", level: slog.LevelInfo, want: otellog.SeverityInfo},
+		{name: "default warn", level: slog.LevelWarn, want: otellog.SeverityWarn},
+		{name: "default error", level: slog.LevelError, want: otellog.SeverityError},
+		{name: "custom info", mapping: custom, level: slog.LevelInfo, want: otellog.SeverityInfo4},
+		{name: "custom error", mapping: custom, level: slog.LevelError, want: otellog.SeverityFatal},
+		{name: "unmapped warn", mapping: custom, level: slog.LevelWarn, want: otellog.SeverityWarn},
+		{name: "unmapped debug", mapping: custom, level: slog.LevelDebug, want: otellog.SeverityDebug},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, logger, recorder := newTestLogger(t, func(h *TracingHandler) *TracingHandler {
+				if tt.mapping == nil {
+					return h
+				}
+				return h.WithSeverityMapping(tt.mapping)
+			})
+
+			logger.Log(ctx, tt.level, "message")
+
+			got := recorder.last(t)["SeverityNumber"]
+			if got.Kind() != otellog.KindInt64 || got.AsInt64() != int64(tt.want) {
+				t.Errorf("SeverityNumber = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFlattenAttributes(t *testing.T) {
+	request := slog.Group("request", slog.String("method", "GET"), slog.Group("user", slog.Int("id", 7)))
+
+	t.Run("enabled", func(t *testing.T) {
+		ctx, logger, recorder := newTestLogger(t, (*TracingHandler).WithFlattenAttributes)
+		logger.InfoContext(ctx, "message", request)
+
+		attrs := recorder.last(t)
+		want := map[string]otellog.Value{
+			"request.method":            otellog.StringValue("GET"),
+			"request.user.id":           otellog.Int64Value(7),
+			"InstrumentationScope.Name": otellog.StringValue("test"),
+		}
+		for key, value := range want {
+			if got, ok := attrs[key]; !ok || !got.Equal(value) {
+				t.Errorf("attribute %s = %v, want %v", key, got, value)
+			}
+		}
+		for key, value := range attrs {
+			if value.Kind() == otellog.KindMap {
+				t.Errorf("attribute %s is still nested: %v", key, value)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx, logger, recorder := newTestLogger(t, nil)
+		logger.InfoContext(ctx, "message", request)
+
+		attrs := recorder.last(t)
+		if got := attrs["request"]; got.Kind() != otellog.KindMap {
+			t.Errorf("attribute request = %v, want a nested group", got)
+		}
+		if _, ok := attrs["request.method"]; ok {
+			t.Error("attribute request.method was flattened although the option is off")
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// baggageAttributes returns the members of bag named in keys, all members when keys is empty, as
+// string attributes with prefix prepended to their keys.
+func baggageAttributes(bag baggage.Baggage, prefix string, keys []string) []attribute.KeyValue {
+	members := bag.Members()
+	if len(keys) > 0 {
+		members = members[:0]
+		for _, key := range keys {
+			if member := bag.Member(key); member.Key() != "" {
+				members = append(members, member)
+			}
+		}
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(members))
+	for _, member := range members {
+		attrs = append(attrs, attribute.String(prefix+member.Key(), member.Value()))
+	}
+
+	return attrs
+}
+
+// baggageProcessor copies baggage members of the parent context onto every span when it starts.
+type baggageProcessor struct {
+	keys []string
+}
+
+// OnStart implements trace.SpanProcessor.
+func (p *baggageProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	if attrs := baggageAttributes(baggage.FromContext(parent), "", p.keys); len(attrs) > 0 {
+		s.SetAttributes(attrs...)
+	}
+}
+
+// OnEnd implements trace.SpanProcessor.
+func (p *baggageProcessor) OnEnd(trace.ReadOnlySpan) {}
+
+// Shutdown implements trace.SpanProcessor.
+func (p *baggageProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush implements trace.SpanProcessor.
+func (p *baggageProcessor) ForceFlush(context.Context) error {
+	return nil
+}
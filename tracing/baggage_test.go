@@ -0,0 +1,137 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// contextWithBaggage returns a context carrying baggage with the members in pairs.
+func contextWithBaggage(t *testing.T, pairs map[string]string) context.Context {
+	t.Helper()
+
+	members := make([]baggage.Member, 0, len(pairs))
+	for key, value := range pairs {
+		member, err := baggage.NewMember(key, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		members = append(members, member)
+	}
+
+	bag, err := baggage.New(members...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return baggage.ContextWithBaggage(context.Background(), bag)
+}
+
+func TestInitBaggageResourceSnapshot(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	exporter := tracetest.NewInMemoryExporter()
+	ctx := contextWithBaggage(t, map[string]string{"tenant_id": "acme", "job": "nightly", "secret": "s3cr3t"})
+	config := Config{
+		BaggageResourceKeys:   []string{"tenant_id", "job"},
+		BaggageResourcePrefix: "baggage.",
+		Attributes:            []attribute.KeyValue{attribute.String("baggage.job", "configured")},
+		DisableGlobal:         true,
+		SyncExport:            true,
+		ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+			return exporter, nil
+		},
+	}
+
+	ctx, traceProvider, err := Init(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background(), traceProvider) })
+
+	// Baggage changed after Init must not reach the resource
+	ctx = baggage.ContextWithoutBaggage(ctx)
+	_, span := TracerFromContext(ctx, "test").Start(ctx, "operation")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	set := spans[0].Resource.Set()
+
+	if got, _ := set.Value("baggage.tenant_id"); got.AsString() != "acme" {
+		t.Errorf("baggage.tenant_id = %q, want acme", got.AsString())
+	}
+	if got, _ := set.Value("baggage.job"); got.AsString() != "configured" {
+		t.Errorf("baggage.job = %q, want configured from Attributes", got.AsString())
+	}
+	for _, key := range []attribute.Key{"baggage.secret", "secret", "tenant_id"} {
+		if set.HasValue(key) {
+			t.Errorf("resource has %s, want it left out", key)
+		}
+	}
+}
+
+func TestInitBaggageSpanAttributes(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want map[attribute.Key]string
+	}{
+		{
+			name: "all members",
+			want: map[attribute.Key]string{"tenant_id": "acme", "request_id": "r-1", "debug": "true"},
+		},
+		{
+			name: "listed members",
+			keys: []string{"tenant_id", "request_id", "missing"},
+			want: map[attribute.Key]string{"tenant_id": "acme", "request_id": "r-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, exporter := initExporter(t, Config{BaggageSpanAttributes: true, BaggageAttributeKeys: tt.keys})
+
+			parent := contextWithBaggage(t, map[string]string{"tenant_id": "acme", "request_id": "r-1", "debug": "true"})
+			_, span := TracerFromContext(ctx, "test").Start(parent, "operation")
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+
+			got := map[attribute.Key]string{}
+			for _, attr := range spans[0].Attributes {
+				got[attr.Key] = attr.Value.AsString()
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("attributes = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("%s = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestInitBaggageSpanAttributesDisabled(t *testing.T) {
+	ctx, exporter := initExporter(t, Config{})
+
+	parent := contextWithBaggage(t, map[string]string{"tenant_id": "acme"})
+	_, span := TracerFromContext(ctx, "test").Start(parent, "operation")
+	span.End()
+
+	if attrs := exporter.GetSpans()[0].Attributes; len(attrs) != 0 {
+		t.Errorf("attributes = %v, want none without BaggageSpanAttributes", attrs)
+	}
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// CanceledKey is the attribute set on spans whose start context was canceled before they ended.
+const CanceledKey = attribute.Key("canceled")
+
+// cancelProcessor marks spans whose start context is canceled while they are running and flushes
+// the provider when such a span ends, so it is exported right away instead of waiting in the batch.
+type cancelProcessor struct {
+	stops sync.Map // trace.SpanID -> func() bool
+	flush func(context.Context) error
+
+	// flushMu guards flushing and again. A single goroutine flushes at a time, spans canceled
+	// meanwhile set again so that it flushes once more instead of starting another goroutine.
+	flushMu  sync.Mutex
+	flushing bool
+	again    bool
+}
+
+// OnStart implements trace.SpanProcessor.
+func (p *cancelProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	if parent.Done() == nil {
+		return
+	}
+
+	stop := context.AfterFunc(parent, func() {
+		s.SetAttributes(CanceledKey.Bool(true))
+	})
+	p.stops.Store(s.SpanContext().SpanID(), stop)
+}
+
+// OnEnd implements trace.SpanProcessor.
+func (p *cancelProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if stop, ok := p.stops.LoadAndDelete(s.SpanContext().SpanID()); ok {
+		stop.(func() bool)()
+	}
+
+	if p.flush == nil || !isCanceled(s) {
+		return
+	}
+
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+
+	if p.flushing {
+		p.again = true
+		return
+	}
+	p.flushing = true
+
+	go p.flushLoop()
+}
+
+// flushLoop flushes the provider until no span was canceled during the last flush.
+func (p *cancelProcessor) flushLoop() {
+	for {
+		_ = p.flush(context.Background())
+
+		p.flushMu.Lock()
+		if !p.again {
+			p.flushing = false
+			p.flushMu.Unlock()
+			return
+		}
+		p.again = false
+		p.flushMu.Unlock()
+	}
+}
+
+// Shutdown implements trace.SpanProcessor.
+func (p *cancelProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush implements trace.SpanProcessor.
+func (p *cancelProcessor) ForceFlush(context.Context) error {
+	return nil
+}
+
+// isCanceled reports whether s carries canceled=true.
+func isCanceled(s trace.ReadOnlySpan) bool {
+	for _, attr := range s.Attributes() {
+		if attr.Key == CanceledKey && attr.Value.AsBool() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCancelProcessorCoalescesFlushes(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	p := &cancelProcessor{flush: func(context.Context) error {
+		calls.Add(1)
+		started <- struct{}{}
+		<-release
+		return nil
+	}}
+
+	span := tracetest.SpanStub{Attributes: []attribute.KeyValue{CanceledKey.Bool(true)}}.Snapshot()
+
+	p.OnEnd(span)
+	<-started
+	for i := 0; i < 100; i++ {
+		p.OnEnd(span)
+	}
+	close(release)
+
+	// The spans canceled during the first flush are exported by a single second one.
+	<-started
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		p.flushMu.Lock()
+		done := !p.flushing
+		p.flushMu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("flush called %d times, want 2", got)
+	}
+}
@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// LinkFromCarrier returns a link to the span context propagated in carrier, e.g. the traceparent
+// header of a Kafka or SQS message, extracted with the propagator configured by Init. The link is
+// empty, and ignored when passed to oteltrace.WithLinks, when carrier holds no valid span context.
+func LinkFromCarrier(carrier propagation.TextMapCarrier) oteltrace.Link {
+	ctx := textMapPropagator().Extract(context.Background(), carrier)
+
+	return oteltrace.LinkFromContext(ctx)
+}
+
+// StartConsumerSpan starts a consumer span named name for a message whose headers are carried by
+// carrier. The span is a child of the span in ctx, if any, and linked to the producer span so
+// batches of messages from different traces can be processed under one parent.
+func StartConsumerSpan(ctx context.Context, name string, carrier propagation.TextMapCarrier, opts ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span) {
+	opts = append([]oteltrace.SpanStartOption{
+		oteltrace.WithSpanKind(oteltrace.SpanKindConsumer),
+		oteltrace.WithAttributes(semconv.MessagingOperationTypeDeliver),
+	}, opts...)
+
+	if link := LinkFromCarrier(carrier); link.SpanContext.IsValid() {
+		opts = append(opts, oteltrace.WithLinks(link))
+	}
+
+	return TracerFromContext(ctx, spanTracerName).Start(ctx, name, opts...)
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestStartConsumerSpan(t *testing.T) {
+	ctx, exporter := initExporter(t, Config{})
+
+	// Produce a message carrying the producer span context in its headers
+	_, producer := TracerFromContext(ctx, "test").Start(ctx, "publish", oteltrace.WithSpanKind(oteltrace.SpanKindProducer))
+	carrier := propagation.MapCarrier{}
+	textMapPropagator().Inject(oteltrace.ContextWithSpan(context.Background(), producer), carrier)
+	producer.End()
+
+	if carrier.Get("traceparent") == "" {
+		t.Fatal("producer span context was not injected into the carrier")
+	}
+
+	link := LinkFromCarrier(carrier)
+	if link.SpanContext.SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("LinkFromCarrier span id = %s, want %s", link.SpanContext.SpanID(), producer.SpanContext().SpanID())
+	}
+
+	_, consumer := StartConsumerSpan(ctx, "process", carrier)
+	consumer.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	span := spans[1]
+	if span.SpanKind != oteltrace.SpanKindConsumer {
+		t.Errorf("span kind = %s, want consumer", span.SpanKind)
+	}
+	if len(span.Links) != 1 {
+		t.Fatalf("got %d links, want 1", len(span.Links))
+	}
+	if got := span.Links[0].SpanContext; !got.Equal(producer.SpanContext().WithRemote(true)) {
+		t.Errorf("link span context = %v, want %v", got, producer.SpanContext())
+	}
+	if span.Parent.IsValid() {
+		t.Errorf("consumer span has parent %s, want a root span linked to the producer", span.Parent.SpanID())
+	}
+
+	found := false
+	for _, attr := range span.Attributes {
+		if attr == semconv.MessagingOperationTypeDeliver {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("attributes = %v, want %v", span.Attributes, semconv.MessagingOperationTypeDeliver)
+	}
+}
+
+func TestStartConsumerSpanWithoutTraceParent(t *testing.T) {
+	ctx, exporter := initExporter(t, Config{})
+
+	if link := LinkFromCarrier(propagation.MapCarrier{}); link.SpanContext.IsValid() {
+		t.Errorf("LinkFromCarrier of an empty carrier = %v, want an invalid span context", link.SpanContext)
+	}
+
+	_, consumer := StartConsumerSpan(ctx, "process", propagation.MapCarrier{})
+	consumer.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if len(spans[0].Links) != 0 {
+		t.Errorf("got %d links, want none", len(spans[0].Links))
+	}
+}
@@ -0,0 +1,22 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// ContextWithTraceParent parses a W3C traceparent header and returns a context carrying the
+// resulting remote span context, e.g. to force a known trace in end-to-end tests.
+func ContextWithTraceParent(ctx context.Context, traceparent string) (context.Context, error) {
+	carrier := propagation.MapCarrier{"traceparent": traceparent}
+	ctx = propagation.TraceContext{}.Extract(ctx, carrier)
+
+	if !oteltrace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+
+	return ctx, nil
+}
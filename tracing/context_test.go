@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestContextWithTraceParent(t *testing.T) {
+	ctx, err := ContextWithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spanContext := oteltrace.SpanContextFromContext(ctx)
+	if got := spanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want 4bf92f3577b34da6a3ce929d0e0e4736", got)
+	}
+	if got := spanContext.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("span id = %s, want 00f067aa0ba902b7", got)
+	}
+	if !spanContext.IsSampled() || !spanContext.IsRemote() {
+		t.Errorf("span context sampled = %t, remote = %t, want both", spanContext.IsSampled(), spanContext.IsRemote())
+	}
+}
+
+func TestContextWithTraceParentInvalid(t *testing.T) {
+	for _, traceparent := range []string{"", "00-4bf92f3577b34da6a3ce929d0e0e4736", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+		if _, err := ContextWithTraceParent(context.Background(), traceparent); err == nil {
+			t.Errorf("ContextWithTraceParent(%q) succeeded, want an error", traceparent)
+		}
+	}
+}
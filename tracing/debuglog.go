@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// debugSpanLogLimit is the maximum number of spans logged per second by the debug span logger.
+const debugSpanLogLimit = 100
+
+// debugLogProcessor logs one line per ended span at debug level, at most debugSpanLogLimit per
+// second. Spans over the limit are counted and the count is reported with the next logged span.
+type debugLogProcessor struct {
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	window  time.Time
+	logged  int
+	dropped int
+}
+
+// newDebugLogProcessor returns a processor logging ended spans to logger.
+func newDebugLogProcessor(logger *slog.Logger) *debugLogProcessor {
+	return &debugLogProcessor{logger: logger, now: time.Now}
+}
+
+// OnStart implements trace.SpanProcessor.
+func (p *debugLogProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+// OnEnd implements trace.SpanProcessor.
+func (p *debugLogProcessor) OnEnd(s trace.ReadOnlySpan) {
+	ctx := context.Background()
+	if !p.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	dropped, ok := p.allow()
+	if !ok {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("name", s.Name()),
+		slog.String("trace_id", s.SpanContext().TraceID().String()),
+		slog.String("span_id", s.SpanContext().SpanID().String()),
+		slog.Duration("duration", s.EndTime().Sub(s.StartTime())),
+		slog.String("status", s.Status().Code.String()),
+	}
+	if dropped > 0 {
+		attrs = append(attrs, slog.Int("dropped", dropped))
+	}
+
+	p.logger.LogAttrs(ctx, slog.LevelDebug, "otelgo span ended", attrs...)
+}
+
+// allow reports whether a span may be logged in the current one second window, along with the
+// number of spans dropped since the last logged one.
+func (p *debugLogProcessor) allow() (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if now := p.now(); now.Sub(p.window) >= time.Second {
+		p.window = now
+		p.logged = 0
+	}
+
+	if p.logged >= debugSpanLogLimit {
+		p.dropped++
+		return 0, false
+	}
+	p.logged++
+
+	dropped := p.dropped
+	p.dropped = 0
+
+	return dropped, true
+}
+
+// Shutdown implements trace.SpanProcessor.
+func (p *debugLogProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush implements trace.SpanProcessor.
+func (p *debugLogProcessor) ForceFlush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// debugLogger returns a logger writing debug level text lines to buf.
+func debugLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestInitDebugSpanLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "enabled", enabled: true},
+		{name: "disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			config := Config{}
+			if tt.enabled {
+				config.DebugSpanLogger = debugLogger(&buf)
+			}
+
+			ctx, _ := initExporter(t, config)
+			_, span := TracerFromContext(ctx, "test").Start(ctx, "checkout")
+			span.SetStatus(codes.Error, "payment declined")
+			span.End()
+
+			got := buf.String()
+			if !tt.enabled {
+				if got != "" {
+					t.Errorf("log output = %q, want nothing without DebugSpanLogger", got)
+				}
+				return
+			}
+
+			for _, want := range []string{
+				`msg="otelgo span ended"`,
+				"name=checkout",
+				"trace_id=" + span.SpanContext().TraceID().String(),
+				"span_id=" + span.SpanContext().SpanID().String(),
+				"status=Error",
+			} {
+				if !strings.Contains(got, want) {
+					t.Errorf("log output %q does not contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDebugLogProcessorRateLimit(t *testing.T) {
+	var buf bytes.Buffer
+	processor := newDebugLogProcessor(debugLogger(&buf))
+	now := time.Now()
+	processor.now = func() time.Time { return now }
+
+	span := tracetest.SpanStub{Name: "operation"}.Snapshot()
+	for range debugSpanLogLimit + 5 {
+		processor.OnEnd(span)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != debugSpanLogLimit {
+		t.Fatalf("logged %d lines within a second, want %d", got, debugSpanLogLimit)
+	}
+
+	// The next window reports the spans dropped in the previous one
+	buf.Reset()
+	now = now.Add(time.Second)
+	processor.OnEnd(span)
+	if got := buf.String(); !strings.Contains(got, "dropped=5") {
+		t.Errorf("log output %q does not report the 5 dropped spans", got)
+	}
+}
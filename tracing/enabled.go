@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// disabledProviders holds the providers created by Init whose spans are never seen: their sampler
+// never samples a trace, or export is disabled and no span processor was given.
+var disabledProviders sync.Map
+
+// isAlwaysOff reports whether sampler drops every span.
+func isAlwaysOff(sampler trace.Sampler) bool {
+	return sampler.Description() == trace.NeverSample().Description()
+}
+
+// IsEnabled reports whether spans started from ctx may be recorded, so callers can skip computing
+// expensive attributes otherwise. It is false when OTEL_SDK_DISABLED is set, when neither ctx nor
+// the global state holds an SDK tracer provider, when the provider samples nothing, or when Init
+// built it with export disabled, by Disabled or OTEL_TRACES_EXPORTER=none, and no SpanProcessors.
+func IsEnabled(ctx context.Context) bool {
+	if common.IsSdkDisabled() {
+		return false
+	}
+
+	if oteltrace.SpanFromContext(ctx).IsRecording() {
+		return true
+	}
+
+	provider, ok := ctx.Value(providerContextKey{}).(*trace.TracerProvider)
+	if !ok {
+		provider = currentProvider.Load()
+	}
+	if provider == nil {
+		// A provider set globally by other code is assumed to sample.
+		provider, ok = otel.GetTracerProvider().(*trace.TracerProvider)
+		if !ok {
+			return false
+		}
+	}
+
+	_, disabled := disabledProviders.Load(provider)
+
+	return !disabled
+}
+
+// SpanIsSampled reports whether the span in ctx is sampled, i.e. will be exported.
+func SpanIsSampled(ctx context.Context) bool {
+	return oteltrace.SpanContextFromContext(ctx).IsSampled()
+}
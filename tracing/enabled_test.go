@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestIsEnabled(t *testing.T) {
+	memoryExporter := func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+		return tracetest.NewInMemoryExporter(), nil
+	}
+
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config Config
+		want   bool
+	}{
+		{name: "exporting", config: Config{ExporterFactory: memoryExporter}, want: true},
+		{name: "disabled", config: Config{Disabled: true}},
+		{name: "exporter none", env: map[string]string{"OTEL_TRACES_EXPORTER": "none"}},
+		{name: "exporter none with a span processor", env: map[string]string{"OTEL_TRACES_EXPORTER": "none"}, config: Config{SpanProcessors: []trace.SpanProcessor{tracetest.NewSpanRecorder()}}, want: true},
+		{name: "always off sampler", config: Config{ExporterFactory: memoryExporter, Sampler: trace.NeverSample()}},
+		{name: "sdk disabled", env: map[string]string{"OTEL_SDK_DISABLED": "true"}, config: Config{ExporterFactory: memoryExporter}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			tt.config.DisableGlobal = true
+			ctx, traceProvider, err := Init(context.Background(), tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+			if got := IsEnabled(ctx); got != tt.want {
+				t.Errorf("IsEnabled() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEnabledWithoutProvider(t *testing.T) {
+	if currentProvider.Load() != nil {
+		t.Skip("a provider initialized by another test is still current")
+	}
+
+	if IsEnabled(context.Background()) {
+		t.Error("IsEnabled() = true without any SDK tracer provider, want false")
+	}
+}
@@ -0,0 +1,300 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// consoleOutput is where the console exporter writes spans.
+var consoleOutput io.Writer = os.Stdout
+
+// newExporter creates the span exporter selected by the configuration and environment. The returned
+// cleanup function, when not nil, releases resources the exporter does not own and must be called
+// after the exporter is shut down.
+func newExporter(ctx context.Context, config Config, tlsConfig *tls.Config) (trace.SpanExporter, func(context.Context) error, error) {
+	// The console exporter writes spans to stdout for local development and never touches the network.
+	if config.ConsoleExporter || os.Getenv("OTEL_TRACES_EXPORTER") == "console" {
+		opts := []stdouttrace.Option{stdouttrace.WithWriter(consoleOutput)}
+		if config.ConsolePrettyPrint {
+			opts = append(opts, stdouttrace.WithPrettyPrint())
+		}
+
+		exporter, err := stdouttrace.New(opts...)
+		return exporter, nil, err
+	}
+
+	var client otlptrace.Client
+	var cleanup func(context.Context) error
+
+	headers := config.Headers
+	if len(headers) == 0 {
+		headers = common.HeadersFromEnv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")
+	}
+
+	grpcOpts := []otlptracegrpc.Option{}
+	httpOpts := []otlptracehttp.Option{
+		otlptracehttp.WithTLSClientConfig(tlsConfig),
+	}
+
+	// A configured endpoint takes precedence over the OTEL_EXPORTER_OTLP_*ENDPOINT variables
+	if config.Endpoint != "" {
+		if err := common.ValidateEndpoint(config.Endpoint); err != nil {
+			return nil, nil, err
+		}
+
+		if strings.Contains(config.Endpoint, "://") {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpointURL(config.Endpoint))
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpointURL(config.Endpoint))
+		} else {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(config.Endpoint))
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+	}
+	if config.EndpointURLPath != "" {
+		httpOpts = append(httpOpts, otlptracehttp.WithURLPath(config.EndpointURLPath))
+	}
+
+	if len(headers) > 0 {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithHeaders(headers))
+		httpOpts = append(httpOpts, otlptracehttp.WithHeaders(headers))
+	}
+
+	if err := common.ValidateInterval("ExportTimeout", config.ExportTimeout); err != nil {
+		return nil, nil, err
+	}
+	if err := common.ValidateInterval("DialTimeout", config.DialTimeout); err != nil {
+		return nil, nil, err
+	}
+
+	timeout := config.ExportTimeout
+	if timeout == 0 {
+		envTimeout, err := common.TimeoutFromEnv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT")
+		if err != nil {
+			return nil, nil, err
+		}
+		timeout = envTimeout
+	}
+	if timeout > 0 {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithTimeout(timeout))
+		httpOpts = append(httpOpts, otlptracehttp.WithTimeout(timeout))
+	}
+
+	if config.Retry != nil {
+		retry, err := config.Retry.jittered(rand.Float64)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
+			Enabled:         retry.Enabled,
+			InitialInterval: retry.InitialInterval,
+			MaxInterval:     retry.MaxInterval,
+			MaxElapsedTime:  retry.MaxElapsedTime,
+		}))
+		httpOpts = append(httpOpts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         retry.Enabled,
+			InitialInterval: retry.InitialInterval,
+			MaxInterval:     retry.MaxInterval,
+			MaxElapsedTime:  retry.MaxElapsedTime,
+		}))
+	}
+
+	compression := config.Compression
+	if compression == "" {
+		compression = common.CompressionFromEnv("OTEL_EXPORTER_OTLP_TRACES_COMPRESSION")
+	}
+	if err := common.ValidateCompression(compression); err != nil {
+		return nil, nil, err
+	}
+
+	switch compression {
+	case "gzip":
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithCompressor("gzip"))
+		httpOpts = append(httpOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	case "none":
+		// gRPC has no compressor named "none", sending uncompressed is its default.
+		httpOpts = append(httpOpts, otlptracehttp.WithCompression(otlptracehttp.NoCompression))
+	}
+
+	protocol, err := common.OtlpProtocol("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if err != nil {
+		return nil, nil, fmt.Errorf("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL: %w", err)
+	}
+
+	switch protocol {
+	case common.ProtocolGRPC:
+		// Configure gRPC dial options to use the custom TLS configuration, a shared connection is
+		// dialed with the TLS settings common to all signals.
+		creds, tlsKey := credentials.NewTLS(tlsConfig), ""
+		if config.ShareGRPCConn {
+			creds, tlsKey, err = common.SharedGrpcCredentials(config.TLS, "TRACES")
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		dialOpts := []grpc.DialOption{
+			grpc.WithTransportCredentials(creds),
+		}
+
+		// Exports wait for the connection to become ready, bounded by the export timeout, instead of
+		// failing fast while the collector restarts.
+		if config.GRPCWaitForReady {
+			dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+		}
+
+		target := common.GrpcEndpoint("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+		if config.Endpoint != "" {
+			target = common.GrpcTarget(config.Endpoint)
+		}
+
+		// The exporter ignores its dial options when given a connection, so the compression it would
+		// have set up is part of the connection settings.
+		if compression == "gzip" {
+			dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor("gzip")))
+		}
+
+		// Observing the connection state or sharing the connection requires owning it, the exporter
+		// then uses it instead of dialing its own. It is released by the cleanup function.
+		var conn *grpc.ClientConn
+		var release func() error
+		if config.ShareGRPCConn {
+			options := fmt.Sprintf("%s,wait-for-ready=%t,compression=%s", tlsKey, config.GRPCWaitForReady, compression)
+			conn, release, err = common.AcquireGrpcConn(target, options, dialOpts...)
+		} else if config.ConnStateCallback != nil || config.DialBlocking {
+			conn, err = grpc.NewClient(target, dialOpts...)
+			if conn != nil {
+				release = conn.Close
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if conn != nil {
+			watchCtx, cancel := context.WithCancel(context.Background())
+			if config.ConnStateCallback != nil {
+				go common.WatchConnState(watchCtx, conn, config.ConnStateCallback)
+			}
+			conn.Connect()
+
+			cleanup = func(context.Context) error {
+				cancel()
+				return release()
+			}
+
+			// A wrong endpoint fails Init instead of every later export.
+			if config.DialBlocking {
+				dialTimeout := config.DialTimeout
+				if dialTimeout == 0 {
+					dialTimeout = defaultDialTimeout
+				}
+				dialCtx, dialCancel := context.WithTimeout(ctx, dialTimeout)
+				err := common.WaitForReady(dialCtx, conn)
+				dialCancel()
+				if err != nil {
+					_ = cleanup(ctx)
+					return nil, nil, fmt.Errorf("failed to connect to OTLP endpoint %s within %s: %w", target, dialTimeout, err)
+				}
+			}
+
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithGRPCConn(conn))
+		} else {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithDialOption(dialOpts...))
+		}
+
+		client = otlptracegrpc.NewClient(grpcOpts...)
+	case common.ProtocolHTTPJSON:
+		// The JSON client does not retry failed exports, so a retry policy would be silently ignored.
+		if config.Retry != nil && config.Retry.Enabled {
+			return nil, nil, errors.New("Retry: not supported with the http/json protocol")
+		}
+		client = newJSONClient(config, tlsConfig, headers, compression, timeout)
+	default:
+		client = otlptracehttp.NewClient(httpOpts...)
+	}
+
+	exporter, err := otlptrace.New(ctx, client)
+	if err != nil {
+		if cleanup != nil {
+			_ = cleanup(ctx)
+		}
+		return nil, nil, err
+	}
+
+	// The HTTP exporter only applies its timeout to single requests, retries included it can take
+	// much longer, so the whole export is bounded as well, whether the timeout is configured or read
+	// from the environment.
+	if timeout > 0 {
+		return &timeoutExporter{SpanExporter: exporter, timeout: timeout}, cleanup, nil
+	}
+
+	return exporter, cleanup, nil
+}
+
+// defaultDialTimeout is how long Init waits for the gRPC connection with DialBlocking when no
+// DialTimeout is set.
+const defaultDialTimeout = 10 * time.Second
+
+// timeoutExporter aborts exports taking longer than timeout.
+type timeoutExporter struct {
+	trace.SpanExporter
+	timeout time.Duration
+}
+
+// ExportSpans implements trace.SpanExporter.
+func (e *timeoutExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
+
+// callbackExporter reports the outcome of every export to an ExportResultCallback.
+type callbackExporter struct {
+	trace.SpanExporter
+	callback common.ExportResultCallback
+}
+
+// ExportSpans implements trace.SpanExporter.
+func (e *callbackExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.callback("traces", len(spans), err)
+	return err
+}
+
+// debugExporter logs a summary of every export batch.
+type debugExporter struct {
+	trace.SpanExporter
+	logger *slog.Logger
+}
+
+// ExportSpans implements trace.SpanExporter.
+func (e *debugExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	start := time.Now()
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+
+	first := ""
+	if len(spans) > 0 {
+		first = spans[0].Name()
+	}
+	common.LogExportBatch(ctx, e.logger, "traces", len(spans), first, time.Since(start), err)
+
+	return err
+}
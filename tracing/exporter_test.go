@@ -0,0 +1,573 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestExportTimeoutFromEnv(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stalled:
+		}
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", "200")
+
+	exporter, cleanup, err := newExporter(context.Background(), Config{Endpoint: server.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = exporter.Shutdown(context.Background())
+		if cleanup != nil {
+			_ = cleanup(context.Background())
+		}
+	}()
+
+	// Retries of the timed out requests would otherwise keep the export going far longer
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err = exporter.ExportSpans(ctx, []trace.ReadOnlySpan{tracetest.SpanStub{Name: "operation"}.Snapshot()})
+	if err == nil {
+		t.Fatal("ExportSpans succeeded against a stalled collector, want a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ExportSpans took %s, want it aborted after the 200ms OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", elapsed)
+	}
+}
+
+func TestExportTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		config Config
+		want   time.Duration
+	}{
+		{name: "unset"},
+		{name: "environment", env: "5000", want: 5 * time.Second},
+		{name: "config over environment", env: "5000", config: Config{ExportTimeout: time.Second}, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", tt.env)
+			t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "")
+
+			config := tt.config
+			config.Endpoint = "http://127.0.0.1:4318"
+			exporter, cleanup, err := newExporter(context.Background(), config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = exporter.Shutdown(context.Background())
+				if cleanup != nil {
+					_ = cleanup(context.Background())
+				}
+			}()
+
+			var got time.Duration
+			if timeout, ok := exporter.(*timeoutExporter); ok {
+				got = timeout.timeout
+			}
+			if got != tt.want {
+				t.Errorf("export timeout = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitRejectsInvalidTimeoutEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", "5s")
+
+	if _, _, err := Init(context.Background(), Config{DisableGlobal: true}); err == nil {
+		t.Error("Init succeeded with an invalid OTEL_EXPORTER_OTLP_TRACES_TIMEOUT, want an error")
+	}
+}
+
+func TestConsoleExporter(t *testing.T) {
+	tests := []struct {
+		name        string
+		exporterEnv string
+		config      Config
+		want        string
+	}{
+		{name: "environment", exporterEnv: "console", want: `"Name":"operation"`},
+		{name: "config", config: Config{ConsoleExporter: true}, want: `"Name":"operation"`},
+		{name: "pretty print", config: Config{ConsoleExporter: true, ConsolePrettyPrint: true}, want: `"Name": "operation"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", tt.exporterEnv)
+
+			output := &bytes.Buffer{}
+			consoleOutput = output
+			defer func() { consoleOutput = os.Stdout }()
+
+			config := tt.config
+			config.DisableGlobal = true
+			_, traceProvider, err := Init(context.Background(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, span := traceProvider.Tracer("test").Start(context.Background(), "operation")
+			span.End()
+			if err := shutdown(context.Background(), traceProvider); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(output.String(), tt.want) {
+				t.Errorf("console output = %s, want it to contain %s", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitConnStateCallback(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing accepts connections on the closed port, so the connection leaves IDLE and fails
+	endpoint := listener.Addr().String()
+	listener.Close()
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+
+	states := make(chan connectivity.State, 10)
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal: true,
+		Endpoint:      endpoint,
+		ConnStateCallback: func(state connectivity.State) {
+			select {
+			case states <- state:
+			default:
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case state := <-states:
+			if state != connectivity.Idle {
+				return
+			}
+		case <-timeout:
+			t.Fatal("no connection state transition was reported")
+		}
+	}
+}
+
+// exportOneSpan exports a span with the exporter newExporter creates for config.
+func exportOneSpan(t *testing.T, config Config) error {
+	t.Helper()
+
+	exporter, cleanup, err := newExporter(context.Background(), config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = exporter.Shutdown(context.Background())
+		if cleanup != nil {
+			_ = cleanup(context.Background())
+		}
+	}()
+
+	return exporter.ExportSpans(context.Background(), []trace.ReadOnlySpan{tracetest.SpanStub{Name: "operation"}.Snapshot()})
+}
+
+func TestCompression(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config Config
+		want   string
+	}{
+		{name: "default", want: ""},
+		{name: "config", config: Config{Compression: "gzip"}, want: "gzip"},
+		{name: "traces environment", env: map[string]string{"OTEL_EXPORTER_OTLP_TRACES_COMPRESSION": "gzip"}, want: "gzip"},
+		{name: "generic environment", env: map[string]string{"OTEL_EXPORTER_OTLP_COMPRESSION": "gzip"}, want: "gzip"},
+		{name: "config over environment", env: map[string]string{"OTEL_EXPORTER_OTLP_TRACES_COMPRESSION": "gzip"}, config: Config{Compression: "none"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encodings := make(chan string, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encodings <- r.Header.Get("Content-Encoding")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_COMPRESSION", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			config := tt.config
+			config.Endpoint = server.URL
+			if err := exportOneSpan(t, config); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := <-encodings; got != tt.want {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingExporter fails every export while fail is set.
+type failingExporter struct {
+	*tracetest.InMemoryExporter
+	fail atomic.Bool
+}
+
+func (e *failingExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if e.fail.Load() {
+		return errors.New("collector unavailable")
+	}
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+func TestExportResultCallback(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	type result struct {
+		signal string
+		count  int
+		err    error
+	}
+	results := []result{}
+
+	exporter := &failingExporter{InMemoryExporter: tracetest.NewInMemoryExporter()}
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal: true,
+		ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+			return exporter, nil
+		},
+		ExportResultCallback: func(signal string, count int, err error) {
+			results = append(results, result{signal: signal, count: count, err: err})
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+	export := func(count int) {
+		for i := 0; i < count; i++ {
+			_, span := traceProvider.Tracer("test").Start(context.Background(), "operation")
+			span.End()
+		}
+		_ = traceProvider.ForceFlush(context.Background())
+	}
+
+	export(3)
+	exporter.fail.Store(true)
+	export(2)
+
+	if len(results) != 2 {
+		t.Fatalf("callback called %d times, want 2: %+v", len(results), results)
+	}
+	if got := results[0]; got.signal != "traces" || got.count != 3 || got.err != nil {
+		t.Errorf("succeeding export reported %+v, want traces, 3 spans and no error", got)
+	}
+	if got := results[1]; got.signal != "traces" || got.count != 2 || got.err == nil {
+		t.Errorf("failing export reported %+v, want traces, 2 spans and an error", got)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		retry   RetryConfig
+		wantErr bool
+	}{
+		{name: "enabled", retry: RetryConfig{Enabled: true, InitialInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond, MaxElapsedTime: 5 * time.Second}},
+		{name: "disabled", retry: RetryConfig{Enabled: false}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The collector is unavailable for the first two requests
+			var requests, delivered atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				delivered.Add(1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+
+			retry := tt.retry
+			err := exportOneSpan(t, Config{Endpoint: server.URL, Retry: &retry})
+			if tt.wantErr {
+				if err == nil || delivered.Load() != 0 {
+					t.Errorf("export delivered %d spans with error %v, want the span dropped with an error", delivered.Load(), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if delivered.Load() != 1 {
+				t.Errorf("collector received the span %d times, want once after the failed requests", delivered.Load())
+			}
+		})
+	}
+}
+
+// traceCollector is a gRPC OTLP trace collector counting the exported spans.
+type traceCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	spans atomic.Int32
+}
+
+func (c *traceCollector) Export(_ context.Context, request *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	for _, rs := range request.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans.Add(int32(len(ss.Spans)))
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func TestGRPCWaitForReady(t *testing.T) {
+	tests := []struct {
+		name         string
+		waitForReady bool
+		wantErr      bool
+	}{
+		{name: "enabled", waitForReady: true},
+		{name: "disabled", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The collector is down when the export starts and comes up on the same port shortly after
+			endpoint := listener.Addr().String()
+			listener.Close()
+
+			collector := &traceCollector{}
+			server := grpc.NewServer()
+			coltracepb.RegisterTraceServiceServer(server, collector)
+			defer server.Stop()
+
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_INSECURE", "true")
+
+			config := Config{Endpoint: endpoint, GRPCWaitForReady: tt.waitForReady, Retry: &RetryConfig{Enabled: false}}
+			exporter, cleanup, err := newExporter(context.Background(), config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = exporter.Shutdown(context.Background())
+				if cleanup != nil {
+					_ = cleanup(context.Background())
+				}
+			}()
+
+			started := time.AfterFunc(300*time.Millisecond, func() {
+				listener, err := net.Listen("tcp", endpoint)
+				if err != nil {
+					return
+				}
+				_ = server.Serve(listener)
+			})
+			defer started.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err = exporter.ExportSpans(ctx, []trace.ReadOnlySpan{tracetest.SpanStub{Name: "operation"}.Snapshot()})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("export succeeded while the collector was down, want it to fail fast")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := collector.spans.Load(); got != 1 {
+				t.Errorf("collector received %d spans, want 1", got)
+			}
+		})
+	}
+}
+
+func TestEndpointOverridesEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantPath string
+	}{
+		{name: "endpoint", wantPath: "/v1/traces"},
+		{name: "endpoint with path", urlPath: "/otlp/v1/traces", wantPath: "/otlp/v1/traces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := make(chan string, 1)
+			configured := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths <- r.URL.Path
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer configured.Close()
+
+			var envRequests atomic.Int32
+			fromEnv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				envRequests.Add(1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer fromEnv.Close()
+
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", fromEnv.URL+"/v1/traces")
+
+			if err := exportOneSpan(t, Config{Endpoint: configured.URL, EndpointURLPath: tt.urlPath}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := <-paths; got != tt.wantPath {
+				t.Errorf("configured endpoint received %s, want %s", got, tt.wantPath)
+			}
+			if got := envRequests.Load(); got != 0 {
+				t.Errorf("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT received %d requests, want none", got)
+			}
+		})
+	}
+}
+
+func TestExportDebugLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	ctx, _ := initExporter(t, Config{ExportDebugLogger: logger})
+	_, span := TracerFromContext(ctx, "test").Start(ctx, "checkout")
+	span.End()
+
+	got := buf.String()
+	for _, want := range []string{`msg="otelgo export batch"`, "signal=traces", "count=1", "first=checkout"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestInitDialBlocking(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing listens on the endpoint once the listener is closed
+	endpoint := listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		name     string
+		config   Config
+		deadline time.Duration
+		wantErr  bool
+	}{
+		{name: "non-blocking", config: Config{DialTimeout: 200 * time.Millisecond}},
+		{name: "dial timeout", config: Config{DialBlocking: true, DialTimeout: 200 * time.Millisecond}, wantErr: true},
+		{name: "context deadline", config: Config{DialBlocking: true}, deadline: 200 * time.Millisecond, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			config := tt.config
+			config.Endpoint = endpoint
+			config.DisableGlobal = true
+
+			start := time.Now()
+			_, traceProvider, err := Init(ctx, config)
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Init took %s, want it bounded by the dial timeout", elapsed)
+			}
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Init failed without DialBlocking: %v", err)
+				}
+				_ = shutdown(context.Background(), traceProvider)
+				return
+			}
+
+			if err == nil {
+				_ = shutdown(context.Background(), traceProvider)
+				t.Fatal("Init succeeded with an unreachable endpoint, want an error")
+			}
+			if want := "failed to connect to OTLP endpoint " + endpoint; !strings.Contains(err.Error(), want) {
+				t.Errorf("error = %q, want it to contain %q", err, want)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error = %v, want it to wrap context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
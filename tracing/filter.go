@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanFilter reports whether span is dropped instead of exported, e.g. health check spans.
+type SpanFilter func(span trace.ReadOnlySpan) bool
+
+// DropSpansNamed returns a SpanFilter dropping the spans with one of the given names, e.g.
+// "GET /healthz".
+func DropSpansNamed(names ...string) SpanFilter {
+	return func(span trace.ReadOnlySpan) bool {
+		return slices.Contains(names, span.Name())
+	}
+}
+
+// filterProcessor keeps the spans dropped by a SpanFilter from reaching the wrapped processor, so
+// they do not take up room in the export queue. Other spans, including the children of dropped
+// spans, are passed on unchanged.
+type filterProcessor struct {
+	trace.SpanProcessor
+	filter SpanFilter
+}
+
+// OnEnd implements trace.SpanProcessor.
+func (p *filterProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if p.filter(s) {
+		return
+	}
+
+	p.SpanProcessor.OnEnd(s)
+}
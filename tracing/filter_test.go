@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestInitDropSpansNamed(t *testing.T) {
+	ctx, exporter := initExporter(t, Config{SpanFilter: DropSpansNamed("GET /healthz", "GET /readyz")})
+	tracer := TracerFromContext(ctx, "test")
+
+	healthCtx, health := tracer.Start(ctx, "GET /healthz")
+	_, child := tracer.Start(healthCtx, "db.ping")
+	child.End()
+	health.End()
+
+	_, ready := tracer.Start(ctx, "GET /readyz")
+	ready.End()
+
+	_, orders := tracer.Start(ctx, "GET /orders")
+	orders.End()
+
+	spans := exporter.GetSpans()
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	if len(names) != 2 || names[0] != "db.ping" || names[1] != "GET /orders" {
+		t.Fatalf("exported spans = %v, want [db.ping GET /orders]", names)
+	}
+
+	// The child of a dropped span keeps its parent, only the export of the parent is skipped
+	if got, want := spans[0].Parent.SpanID(), health.SpanContext().SpanID(); got != want {
+		t.Errorf("child parent = %s, want the dropped %s", got, want)
+	}
+}
+
+func TestInitSpanFilter(t *testing.T) {
+	internal := func(span trace.ReadOnlySpan) bool {
+		return span.SpanKind() == oteltrace.SpanKindInternal
+	}
+	ctx, exporter := initExporter(t, Config{SpanFilter: internal})
+	tracer := TracerFromContext(ctx, "test")
+
+	_, span := tracer.Start(ctx, "helper")
+	span.End()
+	_, span = tracer.Start(ctx, "GET /orders", oteltrace.WithSpanKind(oteltrace.SpanKindServer))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "GET /orders" {
+		t.Errorf("exported %d spans, want only GET /orders", len(spans))
+	}
+}
@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// ForceSampledPropagator wraps propagator so that, when predicate returns true for the context,
+// the injected trace context carries the sampled flag regardless of the local sampling decision.
+// It is meant for downstream systems that ignore unsampled traces, e.g. on specific routes, and
+// is used through Config.Propagators:
+//
+//	tracing.Config{Propagators: []propagation.TextMapPropagator{
+//		tracing.ForceSampledPropagator(propagation.TraceContext{}, isPaymentRoute),
+//	}}
+func ForceSampledPropagator(propagator propagation.TextMapPropagator, predicate func(ctx context.Context) bool) propagation.TextMapPropagator {
+	return forceSampledPropagator{TextMapPropagator: propagator, predicate: predicate}
+}
+
+// forceSampledPropagator sets the sampled flag on the span context injected by the wrapped propagator.
+type forceSampledPropagator struct {
+	propagation.TextMapPropagator
+	predicate func(ctx context.Context) bool
+}
+
+// Inject implements propagation.TextMapPropagator.
+func (p forceSampledPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.IsValid() && !sc.IsSampled() && p.predicate(ctx) {
+		ctx = oteltrace.ContextWithSpanContext(ctx, sc.WithTraceFlags(sc.TraceFlags().WithSampled(true)))
+	}
+
+	p.TextMapPropagator.Inject(ctx, carrier)
+}
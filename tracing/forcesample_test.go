@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// forceKey marks contexts whose trace context must be propagated as sampled.
+type forceKey struct{}
+
+func TestForceSampledPropagator(t *testing.T) {
+	propagator := ForceSampledPropagator(propagation.TraceContext{}, func(ctx context.Context) bool {
+		return ctx.Value(forceKey{}) != nil
+	})
+
+	unsampled := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID: oteltrace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  oteltrace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+
+	tests := []struct {
+		name  string
+		force bool
+		flags oteltrace.TraceFlags
+		want  string
+	}{
+		{name: "forced", force: true, want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "not matching", want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{name: "already sampled", flags: oteltrace.FlagsSampled, want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := oteltrace.ContextWithSpanContext(context.Background(), unsampled.WithTraceFlags(tt.flags))
+			if tt.force {
+				ctx = context.WithValue(ctx, forceKey{}, true)
+			}
+
+			carrier := propagation.MapCarrier{}
+			propagator.Inject(ctx, carrier)
+
+			if got := carrier.Get("traceparent"); got != tt.want {
+				t.Errorf("traceparent = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForceSampledPropagatorThroughConfig(t *testing.T) {
+	initRecorder(t, Config{
+		Sampler: RatioSampler(0),
+		Propagators: []propagation.TextMapPropagator{
+			ForceSampledPropagator(propagation.TraceContext{}, func(context.Context) bool { return true }),
+		},
+	})
+
+	ctx, span := Tracer("test").Start(context.Background(), "operation")
+	defer span.End()
+	if span.SpanContext().IsSampled() {
+		t.Fatal("span was sampled locally, want it dropped")
+	}
+
+	carrier := propagation.MapCarrier{}
+	textMapPropagator().Inject(ctx, carrier)
+
+	sc := oteltrace.SpanContextFromContext(propagation.TraceContext{}.Extract(context.Background(), carrier))
+	if !sc.IsSampled() || sc.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("injected span context = %+v, want the local trace flagged as sampled", sc)
+	}
+}
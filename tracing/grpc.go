@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// GRPCServerOption returns a grpc.ServerOption instrumenting every RPC handled by the server with
+// otelgrpc. Spans come from the provider created by Init and the incoming trace context is
+// extracted with the propagator configured by Init, the same pair used by GRPCDialOption.
+//
+// Stats handlers are used instead of interceptors, which otelgrpc deprecated, so streaming RPCs
+// are covered by the same option.
+func GRPCServerOption(opts ...otelgrpc.Option) grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(grpcOptions(opts)...))
+}
+
+// GRPCDialOption returns a grpc.DialOption instrumenting every RPC sent over the client connection
+// with otelgrpc, injecting the trace context with the propagator configured by Init.
+func GRPCDialOption(opts ...otelgrpc.Option) grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler(grpcOptions(opts)...))
+}
+
+// grpcOptions prepends the tracer provider and propagator configured by Init to the caller's
+// options, so both sides of an RPC use the same propagator set even with DisableGlobal.
+func grpcOptions(opts []otelgrpc.Option) []otelgrpc.Option {
+	defaults := []otelgrpc.Option{
+		otelgrpc.WithTracerProvider(tracerProvider()),
+		otelgrpc.WithPropagators(textMapPropagator()),
+	}
+
+	return append(defaults, opts...)
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCOptionsPropagateTraceContext(t *testing.T) {
+	recorder := initRecorder(t, Config{})
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(GRPCServerOption())
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		GRPCDialOption(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var client, srv oteltrace.SpanContext
+	var serverParent oteltrace.SpanContext
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case oteltrace.SpanKindClient:
+			client = span.SpanContext()
+		case oteltrace.SpanKindServer:
+			srv = span.SpanContext()
+			serverParent = span.Parent()
+		}
+	}
+
+	if !client.IsValid() || !srv.IsValid() {
+		t.Fatalf("want a client and a server span, got %d spans", len(recorder.Ended()))
+	}
+	if srv.TraceID() != client.TraceID() {
+		t.Errorf("server trace id = %s, want the client trace id %s", srv.TraceID(), client.TraceID())
+	}
+	if serverParent.SpanID() != client.SpanID() {
+		t.Errorf("server parent span id = %s, want the client span id %s", serverParent.SpanID(), client.SpanID())
+	}
+}
@@ -0,0 +1,276 @@
+package tracing
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to dir and returns their
+// paths and the certificate.
+func writeClientCert(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "otelgo-client"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath, keyPath := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certPath, keyPath, cert
+}
+
+// handshake describes a request received by an mTLS collector.
+type handshake struct {
+	serverName string
+	client     string
+}
+
+// startMTLSCollector starts an HTTPS collector requiring a client certificate and returns its URL,
+// TLS settings valid for it and the handshakes of the requests it receives. The server
+// certificate is valid for example.com, so ServerName must be sent and verified.
+func startMTLSCollector(t *testing.T) (string, *common.TLSConfig, <-chan handshake) {
+	t.Helper()
+
+	dir := t.TempDir()
+	clientCert, clientKey, cert := writeClientCert(t, dir)
+
+	handshakes := make(chan handshake, 10)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := ""
+		if len(r.TLS.PeerCertificates) > 0 {
+			client = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		select {
+		case handshakes <- handshake{serverName: r.TLS.ServerName, client: client}:
+		default:
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caPath := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return server.URL, &common.TLSConfig{
+		CACertPath:     caPath,
+		ClientCertPath: clientCert,
+		ClientKeyPath:  clientKey,
+		ServerName:     "example.com",
+	}, handshakes
+}
+
+func TestHostMetricsUseConfiguredTLS(t *testing.T) {
+	url, tlsConfig, handshakes := startMTLSCollector(t)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", url)
+
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal:      true,
+		HostMetricsEnabled: true,
+		TLS:                tlsConfig,
+		ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+			return tracetest.NewInMemoryExporter(), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shutting down the tracer provider exports the host metrics a last time
+	if err := shutdown(context.Background(), traceProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-handshakes:
+		if got.serverName != "example.com" {
+			t.Errorf("server name = %q, want example.com", got.serverName)
+		}
+		if got.client != "otelgo-client" {
+			t.Errorf("client certificate = %q, want otelgo-client", got.client)
+		}
+	default:
+		t.Fatal("host metrics were not exported over the configured TLS connection")
+	}
+}
+
+func TestMetricsExportersUseTheirTLS(t *testing.T) {
+	url, tlsConfig, handshakes := startMTLSCollector(t)
+
+	// The span exporter settings are not valid for the metrics collector
+	spanTLS := &common.TLSConfig{ServerName: "traces.example.com"}
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "host fallback", config: Config{HostMetricsEnabled: true, TLS: tlsConfig}},
+		{name: "host", config: Config{HostMetricsEnabled: true, TLS: spanTLS, HostMetricsTLS: tlsConfig}},
+		{name: "runtime fallback", config: Config{RuntimeMetricsEnabled: true, TLS: tlsConfig}},
+		{name: "runtime", config: Config{RuntimeMetricsEnabled: true, TLS: spanTLS, RuntimeMetricsTLS: tlsConfig}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_METRICS_EXPORTER", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+			t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", url)
+
+			config := tt.config
+			config.DisableGlobal = true
+			config.ExporterFactory = func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+				return tracetest.NewInMemoryExporter(), nil
+			}
+			_, traceProvider, err := Init(context.Background(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = shutdown(context.Background(), traceProvider)
+
+			select {
+			case got := <-handshakes:
+				if got.serverName != "example.com" || got.client != "otelgo-client" {
+					t.Errorf("handshake = %+v, want example.com with client otelgo-client", got)
+				}
+			default:
+				t.Fatal("metrics were not exported over the metrics TLS connection")
+			}
+		})
+	}
+}
+
+func TestHostMetricsShareSpanResource(t *testing.T) {
+	resources := make(chan []*commonpb.KeyValue, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		request := &colmetricpb.ExportMetricsServiceRequest{}
+		if err == nil && proto.Unmarshal(body, request) == nil {
+			for _, rm := range request.ResourceMetrics {
+				select {
+				case resources <- rm.Resource.Attributes:
+				default:
+				}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", server.URL+"/v1/metrics")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=staging")
+
+	exporter := tracetest.NewInMemoryExporter()
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal:      true,
+		SyncExport:         true,
+		HostMetricsEnabled: true,
+		Attributes:         []attribute.KeyValue{attribute.String("team", "payments")},
+		ServiceVersion:     "1.4.0",
+		ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+			return exporter, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, started := traceProvider.Tracer("test").Start(context.Background(), "operation")
+	started.End()
+	span := exporter.GetSpans()[0]
+
+	// Shutting down the tracer provider exports the host metrics a last time
+	if err := shutdown(context.Background(), traceProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	var hostAttrs []*commonpb.KeyValue
+	select {
+	case hostAttrs = <-resources:
+	default:
+		t.Fatal("host metrics were not exported")
+	}
+
+	host := map[string]string{}
+	for _, kv := range hostAttrs {
+		host[kv.Key] = kv.Value.String()
+	}
+	if len(host) != span.Resource.Len() {
+		t.Errorf("host metrics resource has %d attributes, want the %d of the span resource", len(host), span.Resource.Len())
+	}
+	for _, attr := range span.Resource.Attributes() {
+		if _, ok := host[string(attr.Key)]; !ok {
+			t.Errorf("%s of the span resource is missing from the host metrics resource", attr.Key)
+		}
+	}
+	for _, key := range []string{"team", "service.version", "deployment.environment"} {
+		want, _ := span.Resource.Set().Value(attribute.Key(key))
+		if got := host[key]; !strings.Contains(got, want.AsString()) {
+			t.Errorf("host metrics %s = %s, want %s as on spans", key, got, want.AsString())
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// HTTPMiddleware wraps next with otelhttp, creating a server span with the standard semconv HTTP
+// attributes for every request. Spans come from the provider created by Init and the incoming
+// trace context is extracted with the propagator configured by Init, even with DisableGlobal.
+// Span names default to the request method, route based names can be produced by passing
+// otelhttp.WithSpanNameFormatter among opts.
+func HTTPMiddleware(next http.Handler, opts ...otelhttp.Option) http.Handler {
+	defaults := []otelhttp.Option{
+		otelhttp.WithTracerProvider(tracerProvider()),
+		otelhttp.WithPropagators(textMapPropagator()),
+		otelhttp.WithSpanNameFormatter(httpSpanName),
+	}
+
+	return otelhttp.NewHandler(next, "", append(defaults, opts...)...)
+}
+
+// httpSpanName names a server span after the request method, following the semconv fallback for
+// spans without a known route, since raw paths would make span names unbounded.
+func httpSpanName(_ string, r *http.Request) string {
+	return r.Method
+}
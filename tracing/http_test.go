@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// initRecorder initializes tracing without exporting or touching the globals and returns a
+// recorder receiving every span.
+func initRecorder(t *testing.T, config Config) *tracetest.SpanRecorder {
+	t.Helper()
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+
+	recorder := tracetest.NewSpanRecorder()
+	config.DisableGlobal = true
+	config.SpanProcessors = append(config.SpanProcessors, recorder)
+
+	_, traceProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background(), traceProvider) })
+
+	return recorder
+}
+
+func TestHTTPMiddlewareContinuesIncomingTrace(t *testing.T) {
+	recorder := initRecorder(t, Config{})
+
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+
+	span := spans[0]
+	if span.SpanKind() != oteltrace.SpanKindServer {
+		t.Errorf("span kind = %s, want server", span.SpanKind())
+	}
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the incoming traceparent trace id", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id = %s, want the incoming traceparent span id", got)
+	}
+	if span.Name() != http.MethodGet {
+		t.Errorf("span name = %q, want %q", span.Name(), http.MethodGet)
+	}
+}
+
+func TestInitSendsHeadersFromEnv(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case headers <- r.Header.Clone():
+		default:
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "Authorization=Bearer%20secret")
+
+	ctx, traceProvider, err := Init(context.Background(), Config{DisableGlobal: true, SyncExport: true, Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+	_, span := traceProvider.Tracer("test").Start(ctx, "operation")
+	span.End()
+
+	if got := (<-headers).Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization header = %q, want %q", got, "Bearer secret")
+	}
+}
+
+func TestInitWithoutPropagatorsDropsEarlierPropagator(t *testing.T) {
+	initRecorder(t, Config{Propagators: []propagation.TextMapPropagator{propagation.TraceContext{}}})
+	if fields := textMapPropagator().Fields(); len(fields) == 0 {
+		t.Fatal("propagator of the first Init has no fields")
+	}
+
+	t.Setenv("OTEL_PROPAGATORS", "none")
+	initRecorder(t, Config{})
+
+	if fields := textMapPropagator().Fields(); len(fields) != 0 {
+		t.Errorf("propagator fields = %v, want none after OTEL_PROPAGATORS=none", fields)
+	}
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// legacyHTTPStatusCodeKey is the status code attribute of older HTTP semantic conventions,
+// still emitted by some instrumentation.
+const legacyHTTPStatusCodeKey = attribute.Key("http.status_code")
+
+// httpStatusExporter sets the status of HTTP spans left Unset by their instrumentation to Error
+// following the semantic conventions: 5xx responses for server spans, 4xx and 5xx for client spans.
+type httpStatusExporter struct {
+	trace.SpanExporter
+}
+
+// ExportSpans implements trace.SpanExporter.
+func (e *httpStatusExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	mapped := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		mapped[i] = span
+		if span.Status().Code == codes.Unset && isHTTPError(span) {
+			mapped[i] = erroredSpan{ReadOnlySpan: span}
+		}
+	}
+
+	return e.SpanExporter.ExportSpans(ctx, mapped)
+}
+
+// isHTTPError reports whether the response status code of span is an error for its kind.
+func isHTTPError(span trace.ReadOnlySpan) bool {
+	threshold := int64(0)
+	switch span.SpanKind() {
+	case oteltrace.SpanKindServer:
+		threshold = 500
+	case oteltrace.SpanKindClient:
+		threshold = 400
+	default:
+		return false
+	}
+
+	for _, attr := range span.Attributes() {
+		if attr.Key == semconv.HTTPResponseStatusCodeKey || attr.Key == legacyHTTPStatusCodeKey {
+			return attr.Value.AsInt64() >= threshold
+		}
+	}
+
+	return false
+}
+
+// erroredSpan is a ReadOnlySpan exported with the Error status. The description is left empty,
+// as the conventions recommend for HTTP status codes.
+type erroredSpan struct {
+	trace.ReadOnlySpan
+}
+
+// Status implements trace.ReadOnlySpan.
+func (s erroredSpan) Status() trace.Status {
+	return trace.Status{Code: codes.Error}
+}
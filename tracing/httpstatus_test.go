@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestHTTPStatusMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   oteltrace.SpanKind
+		attrs  []attribute.KeyValue
+		status codes.Code
+		want   codes.Code
+	}{
+		{name: "server 503", kind: oteltrace.SpanKindServer, attrs: []attribute.KeyValue{attribute.Int("http.response.status_code", 503)}, want: codes.Error},
+		{name: "server 404", kind: oteltrace.SpanKindServer, attrs: []attribute.KeyValue{attribute.Int("http.response.status_code", 404)}, want: codes.Unset},
+		{name: "client 404", kind: oteltrace.SpanKindClient, attrs: []attribute.KeyValue{attribute.Int("http.response.status_code", 404)}, want: codes.Error},
+		{name: "legacy attribute", kind: oteltrace.SpanKindServer, attrs: []attribute.KeyValue{attribute.Int("http.status_code", 502)}, want: codes.Error},
+		{name: "internal span", kind: oteltrace.SpanKindInternal, attrs: []attribute.KeyValue{attribute.Int("http.response.status_code", 503)}, want: codes.Unset},
+		{name: "status kept", kind: oteltrace.SpanKindServer, attrs: []attribute.KeyValue{attribute.Int("http.response.status_code", 503)}, status: codes.Ok, want: codes.Ok},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, exporter := initExporter(t, Config{HTTPStatusMapping: true})
+
+			_, span := TracerFromContext(ctx, "test").Start(ctx, "GET /orders", oteltrace.WithSpanKind(tt.kind), oteltrace.WithAttributes(tt.attrs...))
+			if tt.status != codes.Unset {
+				span.SetStatus(tt.status, "")
+			}
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if got := spans[0].Status.Code; got != tt.want {
+				t.Errorf("exported status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
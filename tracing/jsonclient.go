@@ -0,0 +1,225 @@
+package tracing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// defaultJSONEndpoint is the OTLP/HTTP endpoint used when none is configured.
+	defaultJSONEndpoint = "https://localhost:4318"
+	// defaultJSONURLPath is the path of the OTLP/HTTP traces endpoint.
+	defaultJSONURLPath = "/v1/traces"
+	// defaultJSONTimeout is the time limit of a single export when none is configured.
+	defaultJSONTimeout = 10 * time.Second
+)
+
+// jsonClient is an otlptrace.Client sending spans with the OTLP/HTTP JSON encoding, for collectors
+// that do not accept protobuf. The SDK only implements the protobuf encoding.
+type jsonClient struct {
+	url         string
+	headers     map[string]string
+	compression string
+	client      *http.Client
+}
+
+// newJSONClient returns a client for the endpoint resolved by jsonEndpointURL.
+func newJSONClient(config Config, tlsConfig *tls.Config, headers map[string]string, compression string, timeout time.Duration) *jsonClient {
+	url := jsonEndpointURL(config)
+
+	if timeout == 0 {
+		timeout = defaultJSONTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &jsonClient{
+		url:         url,
+		headers:     headers,
+		compression: compression,
+		client:      &http.Client{Transport: transport, Timeout: timeout},
+	}
+}
+
+// jsonEndpointURL resolves the export URL from config or the environment like the protobuf
+// exporter does: a configured Endpoint URL and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT are used with
+// their path as given, defaulting to /v1/traces when they have none, a host:port Endpoint and the
+// default endpoint use /v1/traces, and OTEL_EXPORTER_OTLP_ENDPOINT gets /v1/traces appended.
+// EndpointURLPath replaces the path in all cases.
+func jsonEndpointURL(config Config) string {
+	url, urlPath := "", ""
+	switch {
+	case strings.Contains(config.Endpoint, "://"):
+		url = config.Endpoint
+		urlPath = pathOf(url)
+	case config.Endpoint != "":
+		url = "https://" + config.Endpoint
+		urlPath = defaultJSONURLPath
+	case os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "":
+		url = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+		urlPath = pathOf(url)
+	case os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "":
+		url = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		urlPath = path.Join(pathOf(url), defaultJSONURLPath)
+	default:
+		url = defaultJSONEndpoint
+	}
+	if config.EndpointURLPath != "" {
+		urlPath = config.EndpointURLPath
+	}
+
+	return url[:len(url)-len(pathOf(url))] + cleanURLPath(urlPath)
+}
+
+// cleanURLPath returns urlPath cleaned and absolute, or /v1/traces when it is empty, like the
+// protobuf exporter.
+func cleanURLPath(urlPath string) string {
+	cleaned := path.Clean(strings.TrimSpace(urlPath))
+	if cleaned == "." {
+		return defaultJSONURLPath
+	}
+	if !path.IsAbs(cleaned) {
+		cleaned = "/" + cleaned
+	}
+
+	return cleaned
+}
+
+// pathOf returns the path of url, starting at the first slash after the scheme and host.
+func pathOf(url string) string {
+	rest := url
+	if _, after, ok := strings.Cut(url, "://"); ok {
+		rest = after
+	}
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[i:]
+	}
+
+	return ""
+}
+
+// Start implements otlptrace.Client.
+func (c *jsonClient) Start(context.Context) error {
+	return nil
+}
+
+// Stop implements otlptrace.Client.
+func (c *jsonClient) Stop(context.Context) error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// UploadTraces implements otlptrace.Client.
+func (c *jsonClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	body, err := marshalJSONSpans(spans)
+	if err != nil {
+		return err
+	}
+
+	if c.compression == "gzip" {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(body); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		body = compressed.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP/JSON export to %s: unexpected status %s", c.url, resp.Status)
+	}
+
+	return nil
+}
+
+// marshalJSONSpans encodes spans as an OTLP/JSON export request. OTLP/JSON differs from the
+// canonical protobuf JSON mapping: enums are integers and trace and span ids are hex encoded
+// instead of base64.
+func marshalJSONSpans(spans []*tracepb.ResourceSpans) ([]byte, error) {
+	request := &coltracepb.ExportTraceServiceRequest{ResourceSpans: spans}
+
+	body, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, err
+	}
+	if err := hexIDs(decoded); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(decoded)
+}
+
+// hexIDs rewrites the base64 traceId, spanId and parentSpanId values found in value as hex.
+func hexIDs(value any) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, member := range v {
+			switch key {
+			case "traceId", "spanId", "parentSpanId":
+				if encoded, ok := member.(string); ok {
+					id, err := base64.StdEncoding.DecodeString(encoded)
+					if err != nil {
+						return fmt.Errorf("decoding %s: %w", key, err)
+					}
+					v[key] = hex.EncodeToString(id)
+				}
+			default:
+				if err := hexIDs(member); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		for _, member := range v {
+			if err := hexIDs(member); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestJSONEndpointURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config Config
+		want   string
+	}{
+		{name: "default", want: "https://localhost:4318/v1/traces"},
+		{name: "endpoint url without path", config: Config{Endpoint: "http://collector:4318"}, want: "http://collector:4318/v1/traces"},
+		{name: "endpoint url with path", config: Config{Endpoint: "http://collector:4318/otlp/traces"}, want: "http://collector:4318/otlp/traces"},
+		{name: "endpoint host and port", config: Config{Endpoint: "collector:4318"}, want: "https://collector:4318/v1/traces"},
+		{name: "endpoint url path", config: Config{Endpoint: "http://collector:4318/otlp", EndpointURLPath: "custom"}, want: "http://collector:4318/custom"},
+		{name: "traces environment", env: map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://collector:4318/traces"}, want: "http://collector:4318/traces"},
+		{name: "generic environment", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/otlp/"}, want: "http://collector:4318/otlp/v1/traces"},
+		{name: "endpoint over environment", env: map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://other:4318"}, config: Config{Endpoint: "http://collector:4318"}, want: "http://collector:4318/v1/traces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			if got := jsonEndpointURL(tt.config); got != tt.want {
+				t.Errorf("jsonEndpointURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalJSONSpans(t *testing.T) {
+	spans := []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Spans: []*tracepb.Span{{
+				TraceId:      []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanId:       []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+				ParentSpanId: []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+				Name:         "operation",
+				Kind:         tracepb.Span_SPAN_KIND_SERVER,
+				Attributes: []*commonpb.KeyValue{{
+					Key:   "spanId",
+					Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "kept"}},
+				}},
+			}},
+		}},
+	}}
+
+	body, err := marshalJSONSpans(spans)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var request struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID      string `json:"traceId"`
+					SpanID       string `json:"spanId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Kind         int    `json:"kind"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		t.Fatal(err)
+	}
+
+	span := request.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	tests := []struct {
+		field string
+		got   any
+		want  any
+	}{
+		{field: "traceId", got: span.TraceID, want: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{field: "spanId", got: span.SpanID, want: "00f067aa0ba902b7"},
+		{field: "parentSpanId", got: span.ParentSpanID, want: "0102030405060708"},
+		{field: "kind", got: span.Kind, want: int(tracepb.Span_SPAN_KIND_SERVER)},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.field, tt.got, tt.want)
+		}
+	}
+
+	if !strings.Contains(string(body), `"stringValue":"kept"`) {
+		t.Errorf("attribute named spanId was rewritten: %s", body)
+	}
+}
+
+func TestHexIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "span id", value: `{"spanId":"APBnqgupArc="}`, want: `{"spanId":"00f067aa0ba902b7"}`},
+		{name: "nested", value: `{"spans":[{"traceId":"AQI=","links":[{"spanId":"AwQ="}]}]}`, want: `{"spans":[{"links":[{"spanId":"0304"}],"traceId":"0102"}]}`},
+		{name: "empty id", value: `{"parentSpanId":""}`, want: `{"parentSpanId":""}`},
+		{name: "non string id", value: `{"spanId":{"nested":true}}`, want: `{"spanId":{"nested":true}}`},
+		{name: "invalid base64", value: `{"traceId":"not base64!"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+
+			err := hexIDs(value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("hexIDs() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("hexIDs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitRejectsRetryWithJSON(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/json")
+
+	_, _, err := Init(context.Background(), Config{
+		DisableGlobal: true,
+		Endpoint:      "http://127.0.0.1:1",
+		Retry:         &RetryConfig{Enabled: true},
+	})
+	if err == nil || !strings.Contains(err.Error(), "Retry") {
+		t.Errorf("Init error = %v, want the Retry setting rejected", err)
+	}
+}
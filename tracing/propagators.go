@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// newPropagator returns the propagator selected by Config.Propagators or OTEL_PROPAGATORS,
+// defaulting to W3C TraceContext and Baggage. It returns nil when propagation is disabled
+// with OTEL_PROPAGATORS=none, in which case the global propagator is left untouched.
+func newPropagator(config Config) (propagation.TextMapPropagator, error) {
+	if len(config.Propagators) > 0 {
+		return propagation.NewCompositeTextMapPropagator(config.Propagators...), nil
+	}
+
+	env := os.Getenv("OTEL_PROPAGATORS")
+	if env == "" {
+		return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}), nil
+	}
+
+	propagators := []propagation.TextMapPropagator{}
+	for _, name := range strings.Split(env, ",") {
+		switch strings.TrimSpace(name) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "none":
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("OTEL_PROPAGATORS: unsupported propagator %q", name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
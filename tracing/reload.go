@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// resourceReloader holds the resource exported with every span, rebuilt on demand from the
+// configured sources, e.g. after the host id file or OTEL_RESOURCE_ATTRIBUTES changed.
+//
+// The tracer provider resource cannot be replaced once created, so the reloaded resource is
+// substituted at export time and spans keep reporting the original one to span processors.
+type resourceReloader struct {
+	current atomic.Pointer[resource.Resource]
+	build   func(ctx context.Context) (*resource.Resource, error)
+}
+
+// newResourceReloader returns a reloader starting with res and rebuilding it with build.
+func newResourceReloader(res *resource.Resource, build func(ctx context.Context) (*resource.Resource, error)) *resourceReloader {
+	r := &resourceReloader{build: build}
+	r.current.Store(res)
+
+	return r
+}
+
+// reload rebuilds the resource, keeping the previous one when the build fails.
+func (r *resourceReloader) reload(ctx context.Context) error {
+	res, err := r.build(ctx)
+	if err != nil {
+		return fmt.Errorf("reloading resource: %w", err)
+	}
+	r.current.Store(res)
+
+	return nil
+}
+
+// watchSIGHUP reloads the resource on every SIGHUP until the returned stop function is called.
+// Reload errors are reported through the global OpenTelemetry error handler.
+func (r *resourceReloader) watchSIGHUP() func(context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-signals:
+				if err := r.reload(context.Background()); err != nil {
+					otel.Handle(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func(context.Context) error {
+		signal.Stop(signals)
+		close(done)
+		<-stopped
+		return nil
+	}
+}
+
+// reloadedResourceExporter exports spans with the current resource of a resourceReloader.
+type reloadedResourceExporter struct {
+	trace.SpanExporter
+	reloader *resourceReloader
+}
+
+// ExportSpans implements trace.SpanExporter.
+func (e *reloadedResourceExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	res := e.reloader.current.Load()
+
+	reloaded := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		reloaded[i] = reloadedSpan{ReadOnlySpan: span, resource: res}
+	}
+
+	return e.SpanExporter.ExportSpans(ctx, reloaded)
+}
+
+// reloadedSpan is a ReadOnlySpan exported with a reloaded resource.
+type reloadedSpan struct {
+	trace.ReadOnlySpan
+	resource *resource.Resource
+}
+
+// Resource implements trace.ReadOnlySpan.
+func (s reloadedSpan) Resource() *resource.Resource {
+	return s.resource
+}
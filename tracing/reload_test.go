@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestReloadResourceOnSIGHUP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machine-id")
+	if err := os.WriteFile(path, []byte("before"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, exporter := initExporter(t, Config{
+		ReloadResourceOnSIGHUP: true,
+		ResourceConfig:         common.ResourceConfig{HostIDPath: path},
+	})
+	tracer := TracerFromContext(ctx, "test")
+
+	// exportedHostID exports a span and returns the host.id of its resource.
+	exportedHostID := func() string {
+		exporter.Reset()
+		_, span := tracer.Start(context.Background(), "operation")
+		span.End()
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("got %d spans, want 1", len(spans))
+		}
+		id, _ := spans[0].Resource.Set().Value("host.id")
+		return id.AsString()
+	}
+
+	if got := exportedHostID(); got != "before" {
+		t.Fatalf("host.id = %q, want before", got)
+	}
+
+	if err := os.WriteFile(path, []byte("after"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	// The resource is reloaded asynchronously by the signal handler
+	deadline := time.Now().Add(2 * time.Second)
+	for exportedHostID() != "after" {
+		if time.Now().After(deadline) {
+			t.Fatal("host.id was not reloaded after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestResourceReloaderKeepsResourceOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machine-id")
+	if err := os.WriteFile(path, []byte("before"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config := common.ResourceConfig{HostIDPath: path}
+	build := func(ctx context.Context) (*resource.Resource, error) {
+		return common.NewResource(ctx, config, nil)
+	}
+
+	res, err := build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader := newResourceReloader(res, build)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.reload(context.Background()); err == nil {
+		t.Fatal("reload succeeded without the host id file, want an error")
+	}
+	if reloader.current.Load() != res {
+		t.Error("failed reload replaced the resource")
+	}
+}
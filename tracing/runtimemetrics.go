@@ -3,7 +3,6 @@ package tracing
 import (
 	"context"
 	"crypto/tls"
-	"log"
 	"time"
 
 	"github.com/wasilak/otelgo/common"
@@ -16,16 +15,11 @@ import (
 	"google.golang.org/grpc/credentials"
 )
 
-func setupRuntimeMetrics(ctx context.Context, res *resource.Resource, interval time.Duration) {
+func setupRuntimeMetrics(ctx context.Context, res *resource.Resource, interval time.Duration, tlsConfig *tls.Config) (*metric.MeterProvider, error) {
 	var err error
 	var exp metric.Exporter
 
 	if common.IsOtlpProtocolGrpc("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL") {
-		// Create a custom TLS configuration
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // WARNING: This skips certificate verification!
-		}
-
 		// Configure gRPC dial options to use the custom TLS configuration
 		grpcOpts := []grpc.DialOption{
 			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
@@ -33,14 +27,10 @@ func setupRuntimeMetrics(ctx context.Context, res *resource.Resource, interval t
 
 		exp, err = otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithDialOption(grpcOpts...))
 	} else {
-		// Create a custom TLS configuration
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // WARNING: Skips certificate verification
-		}
 		exp, err = otlpmetrichttp.New(ctx, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
 	}
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	read := metric.NewPeriodicReader(exp, metric.WithInterval(interval))
@@ -48,6 +38,9 @@ func setupRuntimeMetrics(ctx context.Context, res *resource.Resource, interval t
 
 	err = runtime.Start(runtime.WithMeterProvider(provider))
 	if err != nil {
-		log.Fatal(err)
+		_ = provider.Shutdown(ctx)
+		return nil, err
 	}
+
+	return provider, nil
 }
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// SamplingPriorityKey is the span start attribute used to force a span to be sampled.
+// Any value of 1 or more makes PrioritySampler record and sample the span.
+const SamplingPriorityKey = attribute.Key("sampling.priority")
+
+// prioritySampler samples spans carrying a positive sampling.priority attribute and
+// delegates every other decision to the base sampler.
+type prioritySampler struct {
+	base trace.Sampler
+}
+
+// PrioritySampler wraps base so that spans started with `sampling.priority >= 1` are
+// always recorded and sampled, regardless of the base decision.
+func PrioritySampler(base trace.Sampler) trace.Sampler {
+	return &prioritySampler{base: base}
+}
+
+// ShouldSample implements trace.Sampler.
+func (s *prioritySampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	for _, attr := range p.Attributes {
+		if attr.Key == SamplingPriorityKey && attr.Value.Type() == attribute.INT64 && attr.Value.AsInt64() >= 1 {
+			return trace.SamplingResult{
+				Decision:   trace.RecordAndSample,
+				Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+			}
+		}
+	}
+
+	return s.base.ShouldSample(p)
+}
+
+// Description implements trace.Sampler.
+func (s *prioritySampler) Description() string {
+	return fmt.Sprintf("PrioritySampler{%s}", s.base.Description())
+}
+
+// EffectiveSampler returns the sampler the tracer provider uses: Sampler when set, otherwise the
+// one selected by OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG, defaulting to parent-based
+// always on. Unlike the SDK, which silently falls back to its default, an unknown sampler or an
+// invalid ratio is reported as an error.
+func (c Config) EffectiveSampler() (trace.Sampler, error) {
+	if c.Sampler != nil {
+		return c.Sampler, nil
+	}
+
+	return samplerFromEnv()
+}
+
+// samplerFromEnv parses OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG.
+func samplerFromEnv() (trace.Sampler, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER")))
+	arg, hasArg := os.LookupEnv("OTEL_TRACES_SAMPLER_ARG")
+
+	ratio := func() (float64, error) {
+		if !hasArg {
+			return 1, nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil || value < 0 || value > 1 {
+			return 0, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG: invalid ratio %q for sampler %q, expected a number between 0 and 1", arg, name)
+		}
+		return value, nil
+	}
+
+	switch name {
+	case "", "parentbased_always_on":
+		return trace.ParentBased(trace.AlwaysSample()), nil
+	case "always_on":
+		return trace.AlwaysSample(), nil
+	case "always_off":
+		return trace.NeverSample(), nil
+	case "parentbased_always_off":
+		return trace.ParentBased(trace.NeverSample()), nil
+	case "traceidratio":
+		value, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		return trace.TraceIDRatioBased(value), nil
+	case "parentbased_traceidratio":
+		value, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		return RatioSampler(value), nil
+	default:
+		return nil, fmt.Errorf("OTEL_TRACES_SAMPLER: unsupported sampler %q", name)
+	}
+}
@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// setSamplerEnv sets OTEL_TRACES_SAMPLER to sampler and OTEL_TRACES_SAMPLER_ARG to arg, leaving
+// the argument unset when arg is nil.
+func setSamplerEnv(t *testing.T, sampler string, arg *string) {
+	t.Helper()
+	t.Setenv("OTEL_TRACES_SAMPLER", sampler)
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "")
+	if arg == nil {
+		os.Unsetenv("OTEL_TRACES_SAMPLER_ARG")
+	} else {
+		t.Setenv("OTEL_TRACES_SAMPLER_ARG", *arg)
+	}
+}
+
+func TestSamplerFromEnv(t *testing.T) {
+	arg := func(value string) *string { return &value }
+
+	tests := []struct {
+		name    string
+		sampler string
+		arg     *string
+		want    trace.Sampler
+		wantErr bool
+	}{
+		{name: "missing", want: trace.ParentBased(trace.AlwaysSample())},
+		{name: "always on", sampler: "always_on", want: trace.AlwaysSample()},
+		{name: "always off", sampler: "always_off", want: trace.NeverSample()},
+		{name: "parent based always on", sampler: "parentbased_always_on", want: trace.ParentBased(trace.AlwaysSample())},
+		{name: "parent based always off", sampler: "parentbased_always_off", want: trace.ParentBased(trace.NeverSample())},
+		{name: "case and spaces", sampler: " Always_Off ", want: trace.NeverSample()},
+		{name: "ratio", sampler: "traceidratio", arg: arg("0.25"), want: trace.TraceIDRatioBased(0.25)},
+		{name: "ratio without argument", sampler: "traceidratio", want: trace.TraceIDRatioBased(1)},
+		{name: "parent based ratio", sampler: "parentbased_traceidratio", arg: arg(" 0.5 "), want: trace.ParentBased(trace.TraceIDRatioBased(0.5))},
+		{name: "argument ignored", sampler: "always_on", arg: arg("x"), want: trace.AlwaysSample()},
+		{name: "non numeric ratio", sampler: "traceidratio", arg: arg("half"), wantErr: true},
+		{name: "empty ratio", sampler: "traceidratio", arg: arg(""), wantErr: true},
+		{name: "ratio above one", sampler: "parentbased_traceidratio", arg: arg("1.5"), wantErr: true},
+		{name: "negative ratio", sampler: "traceidratio", arg: arg("-0.1"), wantErr: true},
+		{name: "unknown sampler", sampler: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSamplerEnv(t, tt.sampler, tt.arg)
+
+			got, err := samplerFromEnv()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("samplerFromEnv() = %s, want an error", got.Description())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Description() != tt.want.Description() {
+				t.Errorf("samplerFromEnv() = %s, want %s", got.Description(), tt.want.Description())
+			}
+		})
+	}
+}
+
+func TestInitRejectsInvalidSamplerEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	value := "half"
+	setSamplerEnv(t, "traceidratio", &value)
+
+	if _, _, err := Init(context.Background(), Config{DisableGlobal: true}); err == nil {
+		t.Error("Init succeeded with an invalid OTEL_TRACES_SAMPLER_ARG, want an error")
+	}
+}
+
+// exportSpans starts and ends count spans with opts on a provider initialized with config and
+// returns the number of spans exported.
+func exportSpans(t *testing.T, config Config, count int, opts ...oteltrace.SpanStartOption) int {
+	t.Helper()
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	exporter := tracetest.NewInMemoryExporter()
+	config.DisableGlobal = true
+	config.SyncExport = true
+	config.ExporterFactory = func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+		return exporter, nil
+	}
+
+	_, traceProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+	tracer := traceProvider.Tracer("test")
+	for i := 0; i < count; i++ {
+		_, span := tracer.Start(context.Background(), "operation", opts...)
+		span.End()
+	}
+
+	return len(exporter.GetSpans())
+}
+
+func TestInitSampler(t *testing.T) {
+	tests := []struct {
+		name       string
+		samplerEnv string
+		sampler    trace.Sampler
+		want       int
+	}{
+		{name: "always on", sampler: trace.AlwaysSample(), want: 10},
+		{name: "always off", sampler: trace.NeverSample(), want: 0},
+		{name: "ratio one", sampler: RatioSampler(1), want: 10},
+		{name: "ratio zero", sampler: RatioSampler(0), want: 0},
+		{name: "sampler over environment", samplerEnv: "always_off", sampler: trace.AlwaysSample(), want: 10},
+		{name: "environment when unset", samplerEnv: "always_off", want: 0},
+		{name: "default when unset", want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSamplerEnv(t, tt.samplerEnv, nil)
+
+			if got := exportSpans(t, Config{Sampler: tt.sampler}, 10); got != tt.want {
+				t.Errorf("exported %d of 10 spans, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrioritySampler(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs []attribute.KeyValue
+		want  int
+	}{
+		{name: "priority", attrs: []attribute.KeyValue{SamplingPriorityKey.Int(1)}, want: 1},
+		{name: "higher priority", attrs: []attribute.KeyValue{SamplingPriorityKey.Int(5)}, want: 1},
+		{name: "zero priority", attrs: []attribute.KeyValue{SamplingPriorityKey.Int(0)}, want: 0},
+		{name: "string priority", attrs: []attribute.KeyValue{SamplingPriorityKey.String("1")}, want: 0},
+		{name: "no priority", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Sampler: PrioritySampler(RatioSampler(0))}
+			if got := exportSpans(t, config, 1, oteltrace.WithAttributes(tt.attrs...)); got != tt.want {
+				t.Errorf("exported %d spans, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// spanTracerName is the instrumentation scope of spans started by WithSpan and StartConsumerSpan.
+const spanTracerName = "github.com/wasilak/otelgo/tracing"
+
+// WithSpan runs fn inside a child span named name, using the tracer provider from ctx or Init.
+// An error returned by fn is recorded on the span and sets its status to codes.Error. A panic in
+// fn is recorded the same way before the span is ended and the panic is propagated.
+func WithSpan(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...oteltrace.SpanStartOption) (err error) {
+	ctx, span := TracerFromContext(ctx, spanTracerName).Start(ctx, name, opts...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			span.RecordError(fmt.Errorf("panic: %v", r), oteltrace.WithStackTrace(true))
+			span.SetStatus(codes.Error, fmt.Sprint(r))
+			span.End()
+			panic(r)
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	return fn(ctx)
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestWithSpan(t *testing.T) {
+	tests := []struct {
+		name        string
+		fn          func(ctx context.Context) error
+		wantErr     bool
+		wantPanic   bool
+		wantStatus  codes.Code
+		wantMessage string
+	}{
+		{name: "success", fn: func(context.Context) error { return nil }, wantStatus: codes.Unset},
+		{name: "error", fn: func(context.Context) error { return errors.New("lookup failed") }, wantErr: true, wantStatus: codes.Error, wantMessage: "lookup failed"},
+		{name: "panic", fn: func(context.Context) error { panic("nil map") }, wantPanic: true, wantStatus: codes.Error, wantMessage: "panic: nil map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, exporter := initExporter(t, Config{})
+
+			var err error
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				err = WithSpan(ctx, "operation", tt.fn)
+				return false
+			}()
+			if panicked != tt.wantPanic {
+				t.Fatalf("WithSpan panicked = %t, want %t", panicked, tt.wantPanic)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WithSpan() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d ended spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Status.Code != tt.wantStatus {
+				t.Errorf("status = %s, want %s", span.Status.Code, tt.wantStatus)
+			}
+
+			if tt.wantMessage == "" {
+				if len(span.Events) != 0 {
+					t.Errorf("got events %v, want none", span.Events)
+				}
+				return
+			}
+			if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+				t.Fatalf("got events %v, want one exception event", span.Events)
+			}
+			message := ""
+			for _, attr := range span.Events[0].Attributes {
+				if attr.Key == "exception.message" {
+					message = attr.Value.AsString()
+				}
+			}
+			if message != tt.wantMessage {
+				t.Errorf("exception.message = %q, want %q", message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestWithSpanParent(t *testing.T) {
+	ctx, exporter := initExporter(t, Config{})
+
+	ctx, parent := TracerFromContext(ctx, "test").Start(ctx, "parent")
+	_ = WithSpan(ctx, "child", func(context.Context) error { return nil })
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("child span is not a child of the span in ctx: %+v", spans)
+	}
+}
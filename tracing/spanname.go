@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanNameFormatter returns the name a span is exported under, given its name and attributes,
+// e.g. turning "GET /users/12345" into "GET /users/:id".
+type SpanNameFormatter func(name string, attrs []attribute.KeyValue) string
+
+// spanNameExporter rewrites span names with a SpanNameFormatter before they are exported.
+type spanNameExporter struct {
+	trace.SpanExporter
+	formatter SpanNameFormatter
+}
+
+// ExportSpans implements trace.SpanExporter.
+func (e *spanNameExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	renamed := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		renamed[i] = span
+		if name := e.formatter(span.Name(), span.Attributes()); name != span.Name() {
+			renamed[i] = renamedSpan{ReadOnlySpan: span, name: name}
+		}
+	}
+
+	return e.SpanExporter.ExportSpans(ctx, renamed)
+}
+
+// renamedSpan is a ReadOnlySpan exported under a different name.
+type renamedSpan struct {
+	trace.ReadOnlySpan
+	name string
+}
+
+// Name implements trace.ReadOnlySpan.
+func (s renamedSpan) Name() string {
+	return s.name
+}
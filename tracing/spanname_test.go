@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"regexp"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanNameFormatter(t *testing.T) {
+	ids := regexp.MustCompile(`/[0-9]+`)
+	formatter := func(name string, attrs []attribute.KeyValue) string {
+		for _, attr := range attrs {
+			if attr.Key == "http.route" {
+				return "GET " + attr.Value.AsString()
+			}
+		}
+		return ids.ReplaceAllString(name, "/:id")
+	}
+
+	tests := []struct {
+		name     string
+		spanName string
+		attrs    []attribute.KeyValue
+		want     string
+	}{
+		{name: "ids replaced", spanName: "GET /users/12345/orders/9", want: "GET /users/:id/orders/:id"},
+		{name: "from attributes", spanName: "GET /users/12345", attrs: []attribute.KeyValue{attribute.String("http.route", "/users/{id}")}, want: "GET /users/{id}"},
+		{name: "unchanged", spanName: "GET /health", want: "GET /health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, exporter := initExporter(t, Config{SpanNameFormatter: formatter})
+
+			_, span := TracerFromContext(ctx, "test").Start(ctx, tt.spanName, oteltrace.WithAttributes(tt.attrs...))
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if spans[0].Name != tt.want {
+				t.Errorf("exported span name = %q, want %q", spans[0].Name, tt.want)
+			}
+		})
+	}
+}
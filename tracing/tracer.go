@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// providerContextKey is the context key under which Init stores its tracer provider.
+type providerContextKey struct{}
+
+// currentProvider holds the tracer provider created by the most recent Init call.
+var currentProvider atomic.Pointer[trace.TracerProvider]
+
+// currentPropagator holds the propagator configured by the most recent Init call.
+var currentPropagator atomic.Pointer[propagation.TextMapPropagator]
+
+// Tracer returns a tracer from the provider created by Init, falling back to the global provider
+// when Init has not been called. The instrumentation scope version defaults to the otelgo module
+// version and can be overridden with trace.WithInstrumentationVersion.
+func Tracer(name string, opts ...oteltrace.TracerOption) oteltrace.Tracer {
+	return tracerFrom(tracerProvider(), name, opts...)
+}
+
+// tracerProvider returns the provider created by Init, or the global provider when Init has not
+// been called.
+func tracerProvider() oteltrace.TracerProvider {
+	if current := currentProvider.Load(); current != nil {
+		return current
+	}
+
+	return otel.GetTracerProvider()
+}
+
+// textMapPropagator returns the propagator configured by Init, or the global propagator when Init
+// has not been called.
+func textMapPropagator() propagation.TextMapPropagator {
+	if current := currentPropagator.Load(); current != nil {
+		return *current
+	}
+
+	return otel.GetTextMapPropagator()
+}
+
+// TracerFromContext returns a tracer from the provider stored in ctx by Init, falling back to
+// Tracer when ctx does not carry one.
+func TracerFromContext(ctx context.Context, name string, opts ...oteltrace.TracerOption) oteltrace.Tracer {
+	if provider, ok := ctx.Value(providerContextKey{}).(*trace.TracerProvider); ok {
+		return tracerFrom(provider, name, opts...)
+	}
+
+	return Tracer(name, opts...)
+}
+
+// tracerFrom returns a tracer from provider with the otelgo module version as default scope version.
+func tracerFrom(provider oteltrace.TracerProvider, name string, opts ...oteltrace.TracerOption) oteltrace.Tracer {
+	if version := common.Version(); version != "" {
+		opts = append([]oteltrace.TracerOption{oteltrace.WithInstrumentationVersion(version)}, opts...)
+	}
+
+	return provider.Tracer(name, opts...)
+}
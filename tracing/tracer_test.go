@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wasilak/otelgo/common"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTracerScope(t *testing.T) {
+	ctx, exporter := initExporter(t, Config{})
+
+	tests := []struct {
+		name        string
+		tracer      oteltrace.Tracer
+		wantName    string
+		wantVersion string
+	}{
+		{name: "tracer", tracer: Tracer("github.com/acme/orders"), wantName: "github.com/acme/orders", wantVersion: common.Version()},
+		{name: "tracer from context", tracer: TracerFromContext(ctx, "github.com/acme/payments"), wantName: "github.com/acme/payments", wantVersion: common.Version()},
+		{name: "version override", tracer: Tracer("github.com/acme/orders", oteltrace.WithInstrumentationVersion("v1.2.3")), wantName: "github.com/acme/orders", wantVersion: "v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+
+			_, span := tt.tracer.Start(context.Background(), "operation")
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			scope := spans[0].InstrumentationScope
+			if scope.Name != tt.wantName || scope.Version != tt.wantVersion {
+				t.Errorf("scope = %s@%s, want %s@%s", scope.Name, scope.Version, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestTracerFromContextWithoutProvider(t *testing.T) {
+	_, exporter := initExporter(t, Config{})
+
+	// A context not returned by Init falls back to the provider of the most recent Init
+	_, span := TracerFromContext(context.Background(), "test").Start(context.Background(), "operation")
+	span.End()
+
+	if got := len(exporter.GetSpans()); got != 1 {
+		t.Errorf("exported %d spans, want 1", got)
+	}
+}
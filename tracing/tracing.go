@@ -3,19 +3,20 @@ package tracing
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
-	"dario.cat/mergo"
 	"github.com/wasilak/otelgo/common"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/connectivity"
 )
 
 // Sampler control
@@ -26,111 +27,511 @@ import (
 // @property {bool} HostMetricsEnabled - A boolean value that indicates whether host metrics are
 // enabled or not.
 type Config struct {
-	HostMetricsEnabled     bool          `json:"host_metrics_enabled"`     // HostMetricsEnabled specifies whether host metrics are enabled. Default is false.
-	HostMetricsInterval    time.Duration `json:"host_metrics_interval"`    // HostMetricsInterval specifies the interval at which host metrics are collected. Default is 2 seconds.
-	RuntimeMetricsEnabled  bool          `json:"runtime_metrics_enabled"`  // RuntimeMetricsEnabled specifies whether runtime metrics are enabled. Default is false.
-	RuntimeMetricsInterval time.Duration `json:"runtime_metrics_interval"` // RuntimeMetricsInterval specifies the interval at which runtime metrics are collected. Default is 2 seconds.
+	HostMetricsEnabled     bool                            `json:"host_metrics_enabled"`      // HostMetricsEnabled specifies whether host metrics are enabled. Default is false.
+	HostMetricsInterval    time.Duration                   `json:"host_metrics_interval"`     // HostMetricsInterval specifies the interval at which host metrics are collected, at least MinMetricsInterval. Default is 15 seconds.
+	RuntimeMetricsEnabled  bool                            `json:"runtime_metrics_enabled"`   // RuntimeMetricsEnabled specifies whether runtime metrics are enabled. Default is false.
+	RuntimeMetricsInterval time.Duration                   `json:"runtime_metrics_interval"`  // RuntimeMetricsInterval specifies the interval at which runtime metrics are collected, at least MinMetricsInterval. Default is 15 seconds.
+	MinMetricsInterval     time.Duration                   `json:"min_metrics_interval"`      // MinMetricsInterval specifies the shortest host and runtime metrics interval accepted by Init, guarding against export floods. Default is 1 second.
+	Attributes             []attribute.KeyValue            `json:"attributes"`                // Attributes specifies the attributes to be added to the tracer resource. Default is an empty slice.
+	AttributeMap           map[string]string               `json:"attribute_map"`             // AttributeMap specifies additional string attributes to be added to the tracer resource. Default is nil.
+	BaggageResourceKeys    []string                        `json:"baggage_resource_keys"`     // BaggageResourceKeys specifies the baggage members of the Init context snapshotted once into tracer resource attributes, e.g. for batch workers started with baggage. Attributes take precedence over them. Default is nil, snapshotting nothing.
+	BaggageResourcePrefix  string                          `json:"baggage_resource_prefix"`   // BaggageResourcePrefix specifies the prefix of the resource attribute keys snapshotted from baggage, e.g. "baggage.". Default is empty, using the member keys as is.
+	BaggageSpanAttributes  bool                            `json:"baggage_span_attributes"`   // BaggageSpanAttributes specifies whether baggage members of the parent context, e.g. tenant_id set at the edge, are copied onto every span as attributes when it starts. Default is false.
+	BaggageAttributeKeys   []string                        `json:"baggage_attribute_keys"`    // BaggageAttributeKeys specifies the baggage members copied with BaggageSpanAttributes, excluding all others. Default is nil, copying every member.
+	FlushInterval          time.Duration                   `json:"flush_interval"`            // FlushInterval specifies the maximum delay before queued spans are exported, mapped to the batch span processor timeout. Default is the SDK default (5 seconds or OTEL_BSP_SCHEDULE_DELAY).
+	ServiceVersion         string                          `json:"service_version"`           // ServiceVersion specifies the service.version resource attribute, applied only when non-empty. Default is empty, leaving the version to OTEL_RESOURCE_ATTRIBUTES.
+	ServiceGroup           string                          `json:"service_group"`             // ServiceGroup specifies the service.group resource attribute naming the logical system the service belongs to. Default is empty, using OTEL_SERVICE_GROUP.
+	ResourceConfig         common.ResourceConfig           `json:"resource_config"`           // ResourceConfig specifies how the tracer resource is detected. Default is all detectors enabled.
+	Resource               *resource.Resource              `json:"-"`                         // Resource specifies a prebuilt resource used as is, e.g. one shared by all signals, in which case Attributes, AttributeMap, ServiceVersion, ServiceGroup and ResourceConfig are ignored. Default is nil, detecting the tracer resource.
+	Sampler                trace.Sampler                   `json:"-"`                         // Sampler specifies the sampler used by the tracer provider. Default is nil, validated from OTEL_TRACES_SAMPLER/OTEL_TRACES_SAMPLER_ARG, see EffectiveSampler.
+	ConsoleExporter        bool                            `json:"console_exporter"`          // ConsoleExporter specifies whether spans are written to stdout instead of OTLP, also enabled by OTEL_TRACES_EXPORTER=console. Default is false.
+	ConsolePrettyPrint     bool                            `json:"console_pretty_print"`      // ConsolePrettyPrint specifies whether the console exporter indents its JSON output. Default is false.
+	TLS                    *common.TLSConfig               `json:"tls"`                       // TLS specifies the TLS settings for the span exporter and the host/runtime metrics exporters. Default is nil, which skips server certificate verification.
+	HostMetricsTLS         *common.TLSConfig               `json:"host_metrics_tls"`          // HostMetricsTLS specifies the TLS settings for the host metrics exporter. Default is nil, using TLS.
+	RuntimeMetricsTLS      *common.TLSConfig               `json:"runtime_metrics_tls"`       // RuntimeMetricsTLS specifies the TLS settings for the runtime metrics exporter. Default is nil, using TLS.
+	SpanProcessors         []trace.SpanProcessor           `json:"-"`                         // SpanProcessors specifies additional span processors registered after the batcher. Default is an empty slice.
+	BatchOptions           BatchOptions                    `json:"batch_options"`             // BatchOptions specifies the batch span processor tuning. Default is the SDK defaults.
+	Propagators            []propagation.TextMapPropagator `json:"-"`                         // Propagators specifies the propagators set as the global text map propagator. Default is nil, using OTEL_PROPAGATORS or W3C TraceContext and Baggage.
+	ConnStateCallback      func(connectivity.State)        `json:"-"`                         // ConnStateCallback specifies a function called on every gRPC connection state change of the span exporter. Default is nil.
+	Disabled               bool                            `json:"disabled"`                  // Disabled specifies whether the spans are neither collected nor exported, the programmatic equivalent of OTEL_TRACES_EXPORTER=none or OTEL_EXPORTER_OTLP_TRACES_PROTOCOL=none. Default is false.
+	DisableGlobal          bool                            `json:"disable_global"`            // DisableGlobal specifies whether Init leaves the global tracer provider and propagator untouched. Default is false, setting both.
+	Headers                map[string]string               `json:"headers"`                   // Headers specifies the headers sent with every span export, e.g. authorization. Default is nil, using OTEL_EXPORTER_OTLP_TRACES_HEADERS or OTEL_EXPORTER_OTLP_HEADERS.
+	Compression            string                          `json:"compression"`               // Compression specifies the span export compression, "none" or "gzip". Default is empty, using OTEL_EXPORTER_OTLP_TRACES_COMPRESSION or OTEL_EXPORTER_OTLP_COMPRESSION.
+	ExportResultCallback   common.ExportResultCallback     `json:"-"`                         // ExportResultCallback specifies a function called after every export batch with its size and error. Default is nil.
+	ExportDebugLogger      *slog.Logger                    `json:"-"`                         // ExportDebugLogger specifies a logger receiving a debug level summary of every export batch, e.g. while debugging the collector. Default is nil, logging nothing.
+	DebugSpanLogger        *slog.Logger                    `json:"-"`                         // DebugSpanLogger specifies a logger receiving one debug level line per ended span, with its name, duration, status and trace id, at most 100 per second, alongside the exporters. Default is nil, logging nothing.
+	Retry                  *RetryConfig                    `json:"retry"`                     // Retry specifies the retry policy for failed span exports, an enabled one is rejected with the http/json protocol, which does not retry. Default is nil, keeping the exporter defaults.
+	SpanLimits             *trace.SpanLimits               `json:"span_limits"`               // SpanLimits specifies the limits on span attributes, events and links, applied as-is so start from trace.NewSpanLimits(). Default is nil, using the SDK defaults and OTEL_SPAN_*_LIMIT variables.
+	SyncExport             bool                            `json:"sync_export"`               // SyncExport specifies whether every span is exported synchronously when it ends instead of being batched. Intended for tests only, it slows down instrumented code. Default is false.
+	IDGenerator            trace.IDGenerator               `json:"-"`                         // IDGenerator specifies the generator of trace and span IDs, e.g. for X-Ray compatible IDs. Default is nil, using random IDs.
+	ShareGRPCConn          bool                            `json:"share_grpc_conn"`           // ShareGRPCConn specifies whether the gRPC span exporter shares one connection with the other otelgo exporters using the same endpoint and TLS settings, which follow OTEL_EXPORTER_OTLP_TRACES_INSECURE and the certificate variables unless set explicitly. Default is false, dialing a dedicated connection.
+	GRPCWaitForReady       bool                            `json:"grpc_wait_for_ready"`       // GRPCWaitForReady specifies whether gRPC span exports wait for the connection to become ready, within the export timeout, instead of failing fast. Default is false.
+	DialBlocking           bool                            `json:"dial_blocking"`             // DialBlocking specifies whether Init waits for the gRPC span exporter to connect, failing when the endpoint is unreachable within DialTimeout or the ctx deadline. Ignored for HTTP. Default is false, connecting in the background.
+	DialTimeout            time.Duration                   `json:"dial_timeout"`              // DialTimeout specifies how long Init waits for the gRPC connection with DialBlocking. Default is 10 seconds.
+	ExporterFactory        ExporterFactory                 `json:"-"`                         // ExporterFactory specifies a function creating the span exporter in place of the OTLP exporter, given the TLS settings built from TLS. Default is nil, using OTLP.
+	AdditionalExporters    []trace.SpanExporter            `json:"-"`                         // AdditionalExporters specifies exporters receiving every span alongside the OTLP exporter, each through its own batch span processor, e.g. to send to two collectors during a migration. They are skipped as well when export is disabled, and shut down with the provider or by Init when it fails. Default is an empty slice.
+	SpanNameFormatter      SpanNameFormatter               `json:"-"`                         // SpanNameFormatter specifies a function rewriting span names before export, e.g. to remove IDs from high-cardinality names. Default is nil, exporting names unchanged.
+	SpanFilter             SpanFilter                      `json:"-"`                         // SpanFilter specifies a function selecting the spans dropped before export, e.g. DropSpansNamed("GET /healthz"). Default is nil, exporting every sampled span.
+	Endpoint               string                          `json:"endpoint"`                  // Endpoint specifies the OTLP collector as host:port or as an http(s) URL, taking precedence over OTEL_EXPORTER_OTLP_TRACES_ENDPOINT and OTEL_EXPORTER_OTLP_ENDPOINT. Default is empty, using the environment.
+	EndpointURLPath        string                          `json:"endpoint_url_path"`         // EndpointURLPath specifies the URL path of the HTTP exporter, overriding the path of Endpoint. Default is empty, using /v1/traces.
+	ExportTimeout          time.Duration                   `json:"export_timeout"`            // ExportTimeout specifies how long the exporter waits for a single export request, including retries, before aborting it. Default is 0, using OTEL_EXPORTER_OTLP_TRACES_TIMEOUT or the exporter default of 10 seconds.
+	HTTPStatusMapping      bool                            `json:"http_status_mapping"`       // HTTPStatusMapping specifies whether HTTP spans with an Unset status are exported with the Error status for 5xx responses (server spans) or 4xx and 5xx responses (client spans). Default is false.
+	FlushOnCancel          bool                            `json:"flush_on_cancel"`           // FlushOnCancel specifies whether spans whose context is canceled before they end get canceled=true and are flushed immediately. Default is false.
+	ReloadResourceOnSIGHUP bool                            `json:"reload_resource_on_sighup"` // ReloadResourceOnSIGHUP specifies whether the tracer resource is detected again on SIGHUP, e.g. to pick up a changed HostIDPath file, and exported with later spans. Ignored when Resource is set. Default is false.
 }
 
-// The defaultConfig variable is an instance of the Config struct that specifies the default configuration
-var defaultConfig = Config{
-	HostMetricsEnabled:     false,
-	RuntimeMetricsEnabled:  false,
-	HostMetricsInterval:    2 * time.Second,
-	RuntimeMetricsInterval: 2 * time.Second,
+var (
+	cleanupsMu sync.Mutex
+	// cleanups holds, for every tracer provider, the functions stopping what Init started alongside
+	// it, such as the host and runtime metrics providers.
+	cleanups = map[*trace.TracerProvider][]func(context.Context) error{}
+)
+
+// ExporterFactory creates the span exporter used by Init, receiving the TLS configuration built
+// from Config.TLS. The exporter is shut down with the tracer provider.
+type ExporterFactory func(ctx context.Context, tlsConfig *tls.Config) (trace.SpanExporter, error)
+
+// BatchOptions specifies the tuning of the batch span processor. Zero values keep the SDK defaults,
+// which also honour the OTEL_BSP_* environment variables.
+type BatchOptions struct {
+	MaxQueueSize       int           `json:"max_queue_size"`        // MaxQueueSize specifies the maximum number of spans buffered before new spans are dropped. Default is 2048.
+	MaxExportBatchSize int           `json:"max_export_batch_size"` // MaxExportBatchSize specifies the maximum number of spans exported in one batch. Default is 512.
+	BatchTimeout       time.Duration `json:"batch_timeout"`         // BatchTimeout specifies the maximum delay before a batch is exported, overriding FlushInterval. Default is 5 seconds.
+	ExportTimeout      time.Duration `json:"export_timeout"`        // ExportTimeout specifies how long a single batch export may take. Default is 30 seconds.
+}
+
+// RetryConfig specifies the retry policy of the span exporter for transient export failures.
+type RetryConfig struct {
+	Enabled         bool          `json:"enabled"`          // Enabled specifies whether failed exports are retried.
+	InitialInterval time.Duration `json:"initial_interval"` // InitialInterval specifies the time to wait after the first failure before retrying.
+	MaxInterval     time.Duration `json:"max_interval"`     // MaxInterval specifies the upper bound on the backoff interval.
+	MaxElapsedTime  time.Duration `json:"max_elapsed_time"` // MaxElapsedTime specifies the maximum time spent retrying a batch before it is dropped.
+	Jitter          float64       `json:"jitter"`           // Jitter specifies the fraction, between 0 and 1, by which InitialInterval and MaxInterval are randomly spread per process. Default is 0.
+}
+
+// jittered returns a copy of r whose intervals are scaled by a random factor within 1±Jitter.
+//
+// The exporter already randomizes every retry delay of a process, but all processes start from
+// the same intervals, so instances failing together keep retrying at about the same time.
+// Spreading the base intervals per process breaks that lockstep.
+func (r RetryConfig) jittered(random func() float64) (RetryConfig, error) {
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return r, fmt.Errorf("retry: invalid jitter %v, expected a value between 0 and 1", r.Jitter)
+	}
+	if r.Jitter == 0 {
+		return r, nil
+	}
+
+	factor := 1 + r.Jitter*(2*random()-1)
+	r.InitialInterval = time.Duration(float64(r.InitialInterval) * factor)
+	r.MaxInterval = time.Duration(float64(r.MaxInterval) * factor)
+
+	return r, nil
+}
+
+// options maps the non-zero fields to the corresponding batch span processor options.
+func (b BatchOptions) options() []trace.BatchSpanProcessorOption {
+	opts := []trace.BatchSpanProcessorOption{}
+	if b.MaxQueueSize > 0 {
+		opts = append(opts, trace.WithMaxQueueSize(b.MaxQueueSize))
+	}
+	if b.MaxExportBatchSize > 0 {
+		opts = append(opts, trace.WithMaxExportBatchSize(b.MaxExportBatchSize))
+	}
+	if b.BatchTimeout > 0 {
+		opts = append(opts, trace.WithBatchTimeout(b.BatchTimeout))
+	}
+	if b.ExportTimeout > 0 {
+		opts = append(opts, trace.WithExportTimeout(b.ExportTimeout))
+	}
+
+	return opts
+}
+
+const (
+	// defaultMetricsInterval is the collection interval of host and runtime metrics when none is set.
+	defaultMetricsInterval = 15 * time.Second
+	// defaultMinMetricsInterval is the shortest host and runtime metrics interval accepted when no floor is set.
+	defaultMinMetricsInterval = time.Second
+)
+
+// withDefaults returns a copy of c with the package defaults applied to the fields left unset.
+//
+// Defaults are applied field by field rather than by merging c over a default Config, which could
+// not tell an explicit false or zero from an unset field and would let a non-zero default win.
+// Only fields whose zero value is documented as "use the default" are filled in here.
+func (c Config) withDefaults() (Config, error) {
+	if err := common.ValidateInterval("HostMetricsInterval", c.HostMetricsInterval); err != nil {
+		return c, err
+	}
+	if err := common.ValidateInterval("RuntimeMetricsInterval", c.RuntimeMetricsInterval); err != nil {
+		return c, err
+	}
+
+	if err := common.ValidateInterval("MinMetricsInterval", c.MinMetricsInterval); err != nil {
+		return c, err
+	}
+
+	if c.HostMetricsInterval == 0 {
+		c.HostMetricsInterval = defaultMetricsInterval
+	}
+	if c.RuntimeMetricsInterval == 0 {
+		c.RuntimeMetricsInterval = defaultMetricsInterval
+	}
+	if c.MinMetricsInterval == 0 {
+		c.MinMetricsInterval = defaultMinMetricsInterval
+	}
+
+	if err := common.ValidateMinInterval("HostMetricsInterval", c.HostMetricsInterval, c.MinMetricsInterval); err != nil {
+		return c, err
+	}
+	if err := common.ValidateMinInterval("RuntimeMetricsInterval", c.RuntimeMetricsInterval, c.MinMetricsInterval); err != nil {
+		return c, err
+	}
+
+	return c, nil
 }
 
 // The `Init` function initializes an OpenTelemetry tracer with a specified configuration,
 // exporter, and resource.
 func Init(ctx context.Context, config Config) (context.Context, *trace.TracerProvider, error) {
 
-	// The code `err := mergo.Merge(&defaultConfig, config, mergo.WithOverride)` is using the `mergo`
-	// library to merge the `config` object into the `defaultConfig` object.
-	err := mergo.Merge(&defaultConfig, config, mergo.WithOverride)
+	// The caller's `config` is copied with the defaults applied to unset fields, so every value set
+	// by the caller, including false and zero, is used as written.
+	localConfig, err := config.withDefaults()
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	propagator, err := newPropagator(localConfig)
 	if err != nil {
 		return ctx, nil, err
 	}
 
-	var client otlptrace.Client
+	// With OTEL_SDK_DISABLED=true, OTEL_TRACES_EXPORTER=none, OTEL_EXPORTER_OTLP_TRACES_PROTOCOL=none
+	// or Disabled no exporter is created or used at all, AdditionalExporters included, so Init never
+	// attempts any network setup and the returned provider simply drops spans.
+	exportEnabled := !localConfig.Disabled && !common.IsSignalDisabled("OTEL_TRACES_EXPORTER", "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+
+	// Host and runtime metrics are exported as metrics, so they also follow the metrics switches.
+	metricsEnabled := exportEnabled && !common.IsSignalDisabled("OTEL_METRICS_EXPORTER", "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL")
+
+	// The same TLS configuration is shared by the span exporter and the host/runtime metrics exporters.
+	tlsConfig, err := common.NewTLSConfig(localConfig.TLS)
+	if err != nil {
+		return ctx, nil, err
+	}
 
-	if common.IsOtlpProtocolGrpc("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL") {
-		// Create a custom TLS configuration
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // WARNING: This skips certificate verification!
+	// The host and runtime metrics exporters use the span exporter TLS settings unless they have their own.
+	hostMetricsTLS, runtimeMetricsTLS := tlsConfig, tlsConfig
+	if localConfig.HostMetricsTLS != nil {
+		hostMetricsTLS, err = common.NewTLSConfig(localConfig.HostMetricsTLS)
+		if err != nil {
+			return ctx, nil, err
+		}
+	}
+	if localConfig.RuntimeMetricsTLS != nil {
+		runtimeMetricsTLS, err = common.NewTLSConfig(localConfig.RuntimeMetricsTLS)
+		if err != nil {
+			return ctx, nil, err
 		}
+	}
 
-		// Configure gRPC dial options to use the custom TLS configuration
-		grpcOpts := []grpc.DialOption{
-			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	// Everything started alongside the tracer provider is stopped by these functions on Shutdown,
+	// or right away when Init fails.
+	providerCleanups := []func(context.Context) error{}
+
+	exporters := []trace.SpanExporter{}
+	if exportEnabled && localConfig.ExporterFactory != nil {
+		exporter, err := localConfig.ExporterFactory(ctx, tlsConfig)
+		if err != nil {
+			return ctx, nil, err
+		}
+		exporters = append(exporters, exporter)
+	} else if exportEnabled {
+		exporter, cleanup, err := newExporter(ctx, localConfig, tlsConfig)
+		if err != nil {
+			return ctx, nil, err
 		}
+		if cleanup != nil {
+			providerCleanups = append(providerCleanups, cleanup)
+		}
+		exporters = append(exporters, exporter)
+	}
+	if exportEnabled {
+		exporters = append(exporters, localConfig.AdditionalExporters...)
+	}
 
-		client = otlptracegrpc.NewClient(
-			otlptracegrpc.WithDialOption(grpcOpts...),
-		)
-	} else {
-		// Create a custom TLS configuration
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // WARNING: Skips certificate verification
+	for i, exporter := range exporters {
+		if localConfig.HTTPStatusMapping {
+			exporter = &httpStatusExporter{SpanExporter: exporter}
+		}
+		if localConfig.SpanNameFormatter != nil {
+			exporter = &spanNameExporter{SpanExporter: exporter, formatter: localConfig.SpanNameFormatter}
 		}
+		if localConfig.ExportResultCallback != nil {
+			exporter = &callbackExporter{SpanExporter: exporter, callback: localConfig.ExportResultCallback}
+		}
+		if localConfig.ExportDebugLogger != nil {
+			exporter = &debugExporter{SpanExporter: exporter, logger: localConfig.ExportDebugLogger}
+		}
+		exporters[i] = exporter
+	}
+
+	// User attributes are de-duplicated up front so the last value set for a key
+	// always wins, regardless of how the resource detectors order them.
+	attributes := common.MergeAttributes(localConfig.Attributes, common.AttributesFromMap(localConfig.AttributeMap), common.ServiceVersionAttributes(localConfig.ServiceVersion), common.ServiceGroupAttributes(localConfig.ServiceGroup))
 
-		client = otlptracehttp.NewClient(
-			otlptracehttp.WithTLSClientConfig(tlsConfig),
-		)
+	// Baggage of the Init context is only read here, later changes to it never reach the resource.
+	if len(localConfig.BaggageResourceKeys) > 0 {
+		snapshot := baggageAttributes(baggage.FromContext(ctx), localConfig.BaggageResourcePrefix, localConfig.BaggageResourceKeys)
+		attributes = common.MergeAttributes(snapshot, attributes)
 	}
 
-	exporter, err := otlptrace.New(ctx, client)
-	if err != nil {
-		return ctx, nil, err
+	// The code block is initializing a resource for OpenTelemetry tracing. `common.NewResource()` applies
+	// the standard detectors (host, container, process, telemetry SDK, operating system and environment
+	// variables) according to `ResourceConfig` and adds the user attributes.
+	// A prebuilt resource is used as is, skipping detection entirely.
+	res := localConfig.Resource
+	if res == nil {
+		res, err = common.NewResource(ctx, localConfig.ResourceConfig, attributes)
+		if err != nil {
+			runCleanups(ctx, exporters, providerCleanups)
+			return ctx, nil, err
+		}
+
+		// Spans already handed to the provider keep its resource, so the reloaded one is applied
+		// by the exporters. Host and runtime metrics keep the resource detected here.
+		// The provider also merges OTEL_RESOURCE_ATTRIBUTES into its resource, so with the
+		// environment detector disabled the exporters apply the detected resource as well.
+		if localConfig.ReloadResourceOnSIGHUP || localConfig.ResourceConfig.DisabledDetectors.FromEnv {
+			reloader := newResourceReloader(res, func(ctx context.Context) (*resource.Resource, error) {
+				return common.NewResource(ctx, localConfig.ResourceConfig, attributes)
+			})
+			for i, exporter := range exporters {
+				exporters[i] = &reloadedResourceExporter{SpanExporter: exporter, reloader: reloader}
+			}
+			if localConfig.ReloadResourceOnSIGHUP {
+				providerCleanups = append(providerCleanups, reloader.watchSIGHUP())
+			}
+		}
 	}
 
-	// The code block is initializing a resource for OpenTelemetry tracing. The `resource.New()` function
-	// is called with a context and a series of options (`resource.WithHost()`,
-	// `resource.WithContainer()`, etc.) to configure the resource. These options specify the attributes
-	// of the resource, such as the host, container, process, telemetry SDK, operating system, and
-	// environment variables.
-	res, err := resource.New(ctx,
-		resource.WithHost(),
-		resource.WithContainer(),
-		resource.WithProcess(),
-		resource.WithTelemetrySDK(),
-		resource.WithOS(),
-		resource.WithFromEnv(),
-	)
+	// The `if localConfig.HostMetricsEnabled` condition checks if the `HostMetricsEnabled` field in the
+	// merged `localConfig` variable is set to `true`. If it is `true`, it means that host metrics are enabled.
+	// Host and runtime metrics share the tracer resource, including the user attributes and service
+	// version, so they can be joined with spans in the backend.
+	// The meter providers created for host and runtime metrics are kept so Shutdown can stop them.
+	if metricsEnabled && localConfig.HostMetricsEnabled {
+		provider, err := setupHostMetrics(ctx, res, localConfig.HostMetricsInterval, hostMetricsTLS)
+		if err != nil {
+			runCleanups(ctx, exporters, providerCleanups)
+			return ctx, nil, err
+		}
+		providerCleanups = append(providerCleanups, provider.Shutdown)
+	}
+
+	if metricsEnabled && localConfig.RuntimeMetricsEnabled {
+		provider, err := setupRuntimeMetrics(ctx, res, localConfig.RuntimeMetricsInterval, runtimeMetricsTLS)
+		if err != nil {
+			runCleanups(ctx, exporters, providerCleanups)
+			return ctx, nil, err
+		}
+		providerCleanups = append(providerCleanups, provider.Shutdown)
+	}
+
+	// The batch span processor exports a batch either when it is full or when the batch timeout
+	// elapses, so the timeout acts as the background flush interval.
+	batcherOpts := []trace.BatchSpanProcessorOption{}
+	if localConfig.FlushInterval > 0 {
+		batcherOpts = append(batcherOpts, trace.WithBatchTimeout(localConfig.FlushInterval))
+	}
+	batcherOpts = append(batcherOpts, localConfig.BatchOptions.options()...)
+
+	providerOpts := []trace.TracerProviderOption{
+		trace.WithResource(res),
+	}
+
+	// Registered first, so the baggage attributes are set before any other processor sees the span
+	if localConfig.BaggageSpanAttributes {
+		providerOpts = append(providerOpts, trace.WithSpanProcessor(&baggageProcessor{keys: localConfig.BaggageAttributeKeys}))
+	}
+
+	// Every exporter gets its own processor, so a slow destination does not hold back the others
+	for _, exporter := range exporters {
+		var processor trace.SpanProcessor
+		if localConfig.SyncExport {
+			processor = trace.NewSimpleSpanProcessor(exporter)
+		} else {
+			processor = trace.NewBatchSpanProcessor(exporter, batcherOpts...)
+		}
+		if localConfig.SpanFilter != nil {
+			processor = &filterProcessor{SpanProcessor: processor, filter: localConfig.SpanFilter}
+		}
+		providerOpts = append(providerOpts, trace.WithSpanProcessor(processor))
+	}
+
+	for _, processor := range localConfig.SpanProcessors {
+		providerOpts = append(providerOpts, trace.WithSpanProcessor(processor))
+	}
+
+	if localConfig.DebugSpanLogger != nil {
+		providerOpts = append(providerOpts, trace.WithSpanProcessor(newDebugLogProcessor(localConfig.DebugSpanLogger)))
+	}
+
+	// Registered after the batcher, so a canceled span is already queued when the flush starts
+	var canceler *cancelProcessor
+	if localConfig.FlushOnCancel {
+		canceler = &cancelProcessor{}
+		providerOpts = append(providerOpts, trace.WithSpanProcessor(canceler))
+	}
+
+	if localConfig.IDGenerator != nil {
+		providerOpts = append(providerOpts, trace.WithIDGenerator(localConfig.IDGenerator))
+	}
+
+	if localConfig.SpanLimits != nil {
+		providerOpts = append(providerOpts, trace.WithRawSpanLimits(*localConfig.SpanLimits))
+	}
+
+	// When no sampler is configured it is parsed from OTEL_TRACES_SAMPLER here rather than by the
+	// SDK, so a misconfigured sampler fails Init instead of silently sampling everything.
+	sampler, err := localConfig.EffectiveSampler()
 	if err != nil {
+		runCleanups(ctx, exporters, providerCleanups)
 		return ctx, nil, err
 	}
+	providerOpts = append(providerOpts, trace.WithSampler(sampler))
+
+	// Create the trace provider
+	traceProvider := trace.NewTracerProvider(providerOpts...)
 
-	// The `if defaultConfig.HostMetricsEnabled` condition checks if the `HostMetricsEnabled` field in the
-	// `defaultConfig` variable is set to `true`. If it is `true`, it means that host metrics are enabled.
-	if defaultConfig.HostMetricsEnabled {
-		setupHostMetrics(ctx, res, defaultConfig.HostMetricsInterval)
+	if canceler != nil && len(exporters) > 0 {
+		canceler.flush = traceProvider.ForceFlush
 	}
 
-	if defaultConfig.RuntimeMetricsEnabled {
-		setupRuntimeMetrics(ctx, res, defaultConfig.RuntimeMetricsInterval)
+	if len(providerCleanups) > 0 {
+		cleanupsMu.Lock()
+		cleanups[traceProvider] = providerCleanups
+		cleanupsMu.Unlock()
 	}
 
-	// Create the trace provider
-	traceProvider := trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
-		trace.WithResource(res),
-	)
+	// Spans of a provider that samples nothing, or whose spans reach neither an exporter nor a
+	// processor of the caller, are never seen, so IsEnabled reports it as disabled.
+	if isAlwaysOff(sampler) || (len(exporters) == 0 && len(localConfig.SpanProcessors) == 0 && localConfig.DebugSpanLogger == nil) {
+		disabledProviders.Store(traceProvider, struct{}{})
+	}
+
+	// Remember the provider and propagator for Tracer, TracerFromContext and LinkFromCarrier
+	currentProvider.Store(traceProvider)
+	tracerPropagator := propagator
+	if tracerPropagator == nil {
+		// Propagation was disabled with OTEL_PROPAGATORS=none, so drop the propagator of an earlier Init
+		tracerPropagator = propagation.NewCompositeTextMapPropagator()
+	}
+	currentPropagator.Store(&tracerPropagator)
+	ctx = context.WithValue(ctx, providerContextKey{}, traceProvider)
 
-	// Set the global trace provider
-	otel.SetTracerProvider(traceProvider)
+	// Set the global trace provider and propagator, unless the caller keeps its providers isolated
+	if !localConfig.DisableGlobal {
+		otel.SetTracerProvider(traceProvider)
 
-	// Set the propagator
-	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
-	otel.SetTextMapPropagator(propagator)
+		// Set the propagator, unless propagation was disabled with OTEL_PROPAGATORS=none
+		if propagator != nil {
+			otel.SetTextMapPropagator(propagator)
+		}
+	}
 
 	return ctx, traceProvider, nil
 }
 
-// Shutdown gracefully shuts down the trace provider, ensuring all spans are flushed.
+// SamplerDescription returns the description of the sampler the tracer provider uses, e.g. for
+// startup logs, including one selected by the environment, see EffectiveSampler. It is empty when
+// the environment selects an invalid sampler, which Init reports as an error.
+func (c Config) SamplerDescription() string {
+	sampler, err := c.EffectiveSampler()
+	if err != nil {
+		return ""
+	}
+
+	return sampler.Description()
+}
+
+// SpanLimitsWithAttributes returns the SDK default span limits with the given attribute count and
+// attribute value length limits. A negative value means unlimited.
+func SpanLimitsWithAttributes(countLimit, valueLengthLimit int) *trace.SpanLimits {
+	limits := trace.NewSpanLimits()
+	limits.AttributeCountLimit = countLimit
+	limits.AttributeValueLengthLimit = valueLengthLimit
+
+	return &limits
+}
+
+// RatioSampler returns a parent-based sampler that samples the given fraction of new traces,
+// the programmatic equivalent of OTEL_TRACES_SAMPLER=parentbased_traceidratio.
+func RatioSampler(ratio float64) trace.Sampler {
+	return trace.ParentBased(trace.TraceIDRatioBased(ratio))
+}
+
+// ForceFlush exports all spans buffered by the tracer provider without shutting it down, e.g.
+// before a serverless instance is frozen. The provider remains usable afterwards.
+func ForceFlush(ctx context.Context, traceProvider *trace.TracerProvider) error {
+	return traceProvider.ForceFlush(ctx)
+}
+
+// Shutdown gracefully shuts down the trace provider, ensuring all spans are flushed, along with
+// everything Init started for it, such as the host and runtime metrics providers. Only the first
+// call for a provider does so, later calls do nothing.
 func Shutdown(ctx context.Context, traceProvider *trace.TracerProvider) {
-	defer func() {
-		if err := traceProvider.Shutdown(ctx); err != nil {
-			panic(err)
-		}
-	}()
+	if err := shutdown(ctx, traceProvider); err != nil {
+		panic(err)
+	}
+}
+
+// ShutdownWithTimeout shuts down the trace provider like Shutdown, but returns instead of
+// panicking and never blocks longer than timeout, even when the collector is unreachable.
+// A shutdown cut short by the timeout returns an error matching common.ErrShutdownTimeout.
+func ShutdownWithTimeout(ctx context.Context, traceProvider *trace.TracerProvider, timeout time.Duration) error {
+	return common.ShutdownWithTimeout(ctx, timeout, func(ctx context.Context) error {
+		return shutdown(ctx, traceProvider)
+	})
+}
+
+// shutdown stops the trace provider and runs its cleanup functions, returning their errors.
+// Only the first call for a provider does so, later calls return nil.
+func shutdown(ctx context.Context, traceProvider *trace.TracerProvider) error {
+	return common.ShutdownOnce(ctx, traceProvider, func(ctx context.Context) error {
+		return shutdownProvider(ctx, traceProvider)
+	})
+}
+
+// shutdownProvider stops the trace provider and runs its cleanup functions, returning their errors.
+func shutdownProvider(ctx context.Context, traceProvider *trace.TracerProvider) error {
+	currentProvider.CompareAndSwap(traceProvider, nil)
+	disabledProviders.Delete(traceProvider)
+
+	cleanupsMu.Lock()
+	providerCleanups := cleanups[traceProvider]
+	delete(cleanups, traceProvider)
+	cleanupsMu.Unlock()
+
+	errs := []error{traceProvider.Shutdown(ctx)}
+	for _, cleanup := range providerCleanups {
+		errs = append(errs, cleanup(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// runCleanups shuts down the exporters and calls the given cleanup functions, ignoring errors. It
+// is used to release what was already started when Init fails, as no tracer provider took over
+// the exporters, AdditionalExporters included.
+func runCleanups(ctx context.Context, exporters []trace.SpanExporter, providerCleanups []func(context.Context) error) {
+	// Exporters go first, as the cleanups may close a gRPC connection they still use
+	for _, exporter := range exporters {
+		_ = exporter.Shutdown(ctx)
+	}
+	for _, cleanup := range providerCleanups {
+		_ = cleanup(ctx)
+	}
 }
@@ -0,0 +1,897 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wasilak/otelgo/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// initMetricsProviders initializes tracing and returns the number of host and runtime meter
+// providers Init started, each registering one cleanup.
+func initMetricsProviders(t *testing.T, config Config) int {
+	t.Helper()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:1")
+
+	config.DisableGlobal = true
+	_, traceProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background(), traceProvider) })
+
+	cleanupsMu.Lock()
+	defer cleanupsMu.Unlock()
+
+	return len(cleanups[traceProvider])
+}
+
+func TestInitHostAndRuntimeMetricsFollowMetricsSwitches(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want int
+	}{
+		{name: "enabled", want: 2},
+		{name: "metrics exporter none", env: map[string]string{"OTEL_METRICS_EXPORTER": "none"}},
+		{name: "metrics protocol none", env: map[string]string{"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL": "none"}},
+		{name: "traces exporter none", env: map[string]string{"OTEL_TRACES_EXPORTER": "none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			got := initMetricsProviders(t, Config{HostMetricsEnabled: true, RuntimeMetricsEnabled: true})
+			if got != tt.want {
+				t.Errorf("Init started %d host and runtime meter providers, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitDisabledSkipsAdditionalExporters(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	ctx, traceProvider, err := Init(context.Background(), Config{
+		Disabled:            true,
+		DisableGlobal:       true,
+		SyncExport:          true,
+		AdditionalExporters: []trace.SpanExporter{exporter},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+	_, span := traceProvider.Tracer("test").Start(ctx, "operation")
+	span.End()
+
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("additional exporter received %d spans from a disabled provider", len(spans))
+	}
+}
+
+// keptExporter is an in-memory exporter keeping its spans after Shutdown.
+type keptExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (e keptExporter) Shutdown(context.Context) error { return nil }
+
+func TestInitAdditionalExporters(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	primary := keptExporter{tracetest.NewInMemoryExporter()}
+	additional := keptExporter{tracetest.NewInMemoryExporter()}
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal: true,
+		ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+			return primary, nil
+		},
+		AdditionalExporters: []trace.SpanExporter{additional},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer := traceProvider.Tracer("test")
+	for _, name := range []string{"first", "second", "third"} {
+		_, span := tracer.Start(context.Background(), name)
+		span.End()
+	}
+
+	// The spans are still batched, Shutdown has to flush them to both exporters
+	if err := shutdown(context.Background(), traceProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	spanIDs := func(spans tracetest.SpanStubs) []string {
+		ids := []string{}
+		for _, span := range spans {
+			ids = append(ids, span.SpanContext.SpanID().String())
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	want := spanIDs(primary.GetSpans())
+	if len(want) != 3 {
+		t.Fatalf("primary exporter received %d spans, want 3", len(want))
+	}
+	if got := spanIDs(additional.GetSpans()); !reflect.DeepEqual(got, want) {
+		t.Errorf("additional exporter received spans %v, want %v", got, want)
+	}
+}
+
+func TestForceFlush(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	exporter := tracetest.NewInMemoryExporter()
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal: true,
+		ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+			return exporter, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+	// The provider keeps exporting after a flush
+	for want := 1; want <= 2; want++ {
+		_, span := traceProvider.Tracer("test").Start(context.Background(), "operation")
+		span.End()
+
+		if err := ForceFlush(context.Background(), traceProvider); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(exporter.GetSpans()); got != want {
+			t.Fatalf("exported %d spans after flush %d, want %d", got, want, want)
+		}
+	}
+}
+
+func TestSamplerDescription(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		sampler trace.Sampler
+		want    string
+	}{
+		{name: "default", want: trace.ParentBased(trace.AlwaysSample()).Description()},
+		{name: "configured", sampler: trace.NeverSample(), env: map[string]string{"OTEL_TRACES_SAMPLER": "always_on"}, want: trace.NeverSample().Description()},
+		{name: "environment", env: map[string]string{"OTEL_TRACES_SAMPLER": "traceidratio", "OTEL_TRACES_SAMPLER_ARG": "0.25"}, want: trace.TraceIDRatioBased(0.25).Description()},
+		{name: "invalid environment", env: map[string]string{"OTEL_TRACES_SAMPLER": "sometimes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER", "")
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", "")
+			os.Unsetenv("OTEL_TRACES_SAMPLER_ARG")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			if got := (Config{Sampler: tt.sampler}).SamplerDescription(); got != tt.want {
+				t.Errorf("SamplerDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitStartsConfiguredHostAndRuntimeMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   int
+	}{
+		{name: "none"},
+		{name: "host", config: Config{HostMetricsEnabled: true}, want: 1},
+		{name: "runtime", config: Config{RuntimeMetricsEnabled: true}, want: 1},
+		{name: "both", config: Config{HostMetricsEnabled: true, RuntimeMetricsEnabled: true, HostMetricsInterval: 30 * time.Second}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := initMetricsProviders(t, tt.config); got != tt.want {
+				t.Errorf("Init started %d host and runtime meter providers, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitReturnsHostAndRuntimeMetricsErrors(t *testing.T) {
+	invalidTLS := &common.TLSConfig{ClientCertPath: "client.pem"}
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "host", config: Config{HostMetricsEnabled: true, HostMetricsTLS: invalidTLS}},
+		{name: "runtime", config: Config{RuntimeMetricsEnabled: true, RuntimeMetricsTLS: invalidTLS}},
+		{name: "missing certificate", config: Config{HostMetricsEnabled: true, HostMetricsTLS: &common.TLSConfig{CACertPath: filepath.Join(t.TempDir(), "missing.pem")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:1")
+			tt.config.DisableGlobal = true
+
+			_, traceProvider, err := Init(context.Background(), tt.config)
+			if err == nil {
+				_ = shutdown(context.Background(), traceProvider)
+				t.Fatal("Init succeeded, want an error")
+			}
+			if traceProvider != nil {
+				t.Errorf("Init returned a provider along with error %v", err)
+			}
+		})
+	}
+}
+
+func TestShutdownStopsHostAndRuntimeMetrics(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:1")
+	before := runtime.NumGoroutine()
+
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal:         true,
+		HostMetricsEnabled:    true,
+		RuntimeMetricsEnabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ShutdownWithTimeout(context.Background(), traceProvider, 5*time.Second); err != nil {
+		t.Logf("shutdown error, expected without a collector: %v", err)
+	}
+
+	// Goroutines exit shortly after their provider is shut down.
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		buf := make([]byte, 1<<16)
+		t.Errorf("%d goroutines left running after Shutdown:\n%s", after-before, buf[:runtime.Stack(buf, true)])
+	}
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		want    Config
+		wantErr bool
+	}{
+		{
+			name:   "unset intervals",
+			config: Config{},
+			want:   Config{HostMetricsInterval: defaultMetricsInterval, RuntimeMetricsInterval: defaultMetricsInterval, MinMetricsInterval: defaultMinMetricsInterval},
+		},
+		{
+			name:   "explicit values",
+			config: Config{HostMetricsEnabled: true, HostMetricsInterval: 30 * time.Second, RuntimeMetricsInterval: 5 * time.Second, MinMetricsInterval: 5 * time.Second},
+			want:   Config{HostMetricsEnabled: true, HostMetricsInterval: 30 * time.Second, RuntimeMetricsInterval: 5 * time.Second, MinMetricsInterval: 5 * time.Second},
+		},
+		{
+			name:   "explicit false",
+			config: Config{HostMetricsEnabled: false, RuntimeMetricsEnabled: false, Disabled: false},
+			want:   Config{HostMetricsInterval: defaultMetricsInterval, RuntimeMetricsInterval: defaultMetricsInterval, MinMetricsInterval: defaultMinMetricsInterval},
+		},
+		{name: "negative interval", config: Config{HostMetricsInterval: -time.Second}, wantErr: true},
+		{
+			name:   "lowered floor",
+			config: Config{HostMetricsInterval: 500 * time.Millisecond, MinMetricsInterval: 100 * time.Millisecond},
+			want:   Config{HostMetricsInterval: 500 * time.Millisecond, RuntimeMetricsInterval: defaultMetricsInterval, MinMetricsInterval: 100 * time.Millisecond},
+		},
+		{name: "below the floor", config: Config{RuntimeMetricsInterval: 500 * time.Millisecond}, wantErr: true},
+		{name: "host below the floor", config: Config{HostMetricsInterval: time.Millisecond}, wantErr: true},
+		{name: "below a raised floor", config: Config{HostMetricsInterval: 2 * time.Second, MinMetricsInterval: 5 * time.Second}, wantErr: true},
+		{name: "negative floor", config: Config{MinMetricsInterval: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.withDefaults()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("withDefaults() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.HostMetricsEnabled != tt.want.HostMetricsEnabled || got.RuntimeMetricsEnabled != tt.want.RuntimeMetricsEnabled || got.Disabled != tt.want.Disabled {
+				t.Errorf("withDefaults() switches = %t/%t/%t, want %t/%t/%t", got.HostMetricsEnabled, got.RuntimeMetricsEnabled, got.Disabled, tt.want.HostMetricsEnabled, tt.want.RuntimeMetricsEnabled, tt.want.Disabled)
+			}
+			if got.HostMetricsInterval != tt.want.HostMetricsInterval || got.RuntimeMetricsInterval != tt.want.RuntimeMetricsInterval || got.MinMetricsInterval != tt.want.MinMetricsInterval {
+				t.Errorf("withDefaults() intervals = %s/%s/%s, want %s/%s/%s", got.HostMetricsInterval, got.RuntimeMetricsInterval, got.MinMetricsInterval, tt.want.HostMetricsInterval, tt.want.RuntimeMetricsInterval, tt.want.MinMetricsInterval)
+			}
+		})
+	}
+}
+
+func TestInitRejectsShortMetricsInterval(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+
+	for _, config := range []Config{{HostMetricsInterval: time.Millisecond}, {RuntimeMetricsInterval: time.Millisecond}} {
+		config.DisableGlobal = true
+		if _, traceProvider, err := Init(context.Background(), config); err == nil {
+			_ = shutdown(context.Background(), traceProvider)
+			t.Errorf("Init succeeded with intervals %s/%s, want an error", config.HostMetricsInterval, config.RuntimeMetricsInterval)
+		}
+	}
+}
+
+func TestRetryConfigJittered(t *testing.T) {
+	base := RetryConfig{Enabled: true, InitialInterval: 10 * time.Second, MaxInterval: 60 * time.Second, MaxElapsedTime: 5 * time.Minute}
+	withJitter := func(jitter float64) RetryConfig {
+		r := base
+		r.Jitter = jitter
+		return r
+	}
+
+	tests := []struct {
+		name        string
+		retry       RetryConfig
+		random      float64
+		wantInitial time.Duration
+		wantMax     time.Duration
+		wantErr     bool
+	}{
+		{name: "no jitter", retry: base, random: 0, wantInitial: 10 * time.Second, wantMax: 60 * time.Second},
+		{name: "lower bound", retry: withJitter(0.2), random: 0, wantInitial: 8 * time.Second, wantMax: 48 * time.Second},
+		{name: "middle", retry: withJitter(0.2), random: 0.5, wantInitial: 10 * time.Second, wantMax: 60 * time.Second},
+		{name: "upper bound", retry: withJitter(0.2), random: 1, wantInitial: 12 * time.Second, wantMax: 72 * time.Second},
+		{name: "full jitter", retry: withJitter(1), random: 0.75, wantInitial: 15 * time.Second, wantMax: 90 * time.Second},
+		{name: "negative jitter", retry: withJitter(-0.1), wantErr: true},
+		{name: "jitter above one", retry: withJitter(1.5), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.retry.jittered(func() float64 { return tt.random })
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("jittered() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.InitialInterval != tt.wantInitial || got.MaxInterval != tt.wantMax {
+				t.Errorf("jittered() intervals = %s/%s, want %s/%s", got.InitialInterval, got.MaxInterval, tt.wantInitial, tt.wantMax)
+			}
+			if got.Enabled != base.Enabled || got.MaxElapsedTime != base.MaxElapsedTime {
+				t.Errorf("jittered() changed Enabled or MaxElapsedTime: %+v", got)
+			}
+		})
+	}
+}
+
+func TestRetryConfigJitteredSpreadsWithinBound(t *testing.T) {
+	retry := RetryConfig{InitialInterval: time.Second, MaxInterval: 10 * time.Second, Jitter: 0.3}
+	randoms := []float64{0, 0.1, 0.35, 0.5, 0.8, 0.999}
+
+	seen := map[time.Duration]bool{}
+	for _, random := range randoms {
+		got, err := retry.jittered(func() float64 { return random })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.InitialInterval < 700*time.Millisecond || got.InitialInterval > 1300*time.Millisecond {
+			t.Errorf("InitialInterval %s for random %v is outside 1s±30%%", got.InitialInterval, random)
+		}
+		seen[got.InitialInterval] = true
+	}
+
+	if len(seen) != len(randoms) {
+		t.Errorf("got %d distinct intervals for %d random values, want them to vary", len(seen), len(randoms))
+	}
+}
+
+// countingExporter counts how often it was shut down.
+type countingExporter struct {
+	*tracetest.InMemoryExporter
+	shutdowns atomic.Int32
+}
+
+func (e *countingExporter) Shutdown(ctx context.Context) error {
+	e.shutdowns.Add(1)
+	return e.InMemoryExporter.Shutdown(ctx)
+}
+
+func TestInitFailureShutsDownExporters(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func(exporter trace.SpanExporter) Config
+	}{
+		{
+			name: "exporter factory",
+			config: func(exporter trace.SpanExporter) Config {
+				return Config{ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+					return exporter, nil
+				}}
+			},
+		},
+		{
+			name: "additional exporter",
+			config: func(exporter trace.SpanExporter) Config {
+				return Config{
+					ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+						return tracetest.NewInMemoryExporter(), nil
+					},
+					AdditionalExporters: []trace.SpanExporter{exporter},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			// The sampler is read after the exporters are created, failing Init late
+			value := "half"
+			setSamplerEnv(t, "traceidratio", &value)
+
+			exporter := &countingExporter{InMemoryExporter: tracetest.NewInMemoryExporter()}
+			config := tt.config(exporter)
+			config.DisableGlobal = true
+
+			if _, _, err := Init(context.Background(), config); err == nil {
+				t.Fatal("Init succeeded with an invalid OTEL_TRACES_SAMPLER_ARG, want an error")
+			}
+			if got := exporter.shutdowns.Load(); got != 1 {
+				t.Errorf("exporter shut down %d times, want 1", got)
+			}
+		})
+	}
+}
+
+// spanResource returns the resource of a span ended on a provider initialized with config.
+func spanResource(t *testing.T, config Config) *resource.Resource {
+	t.Helper()
+
+	recorder := initRecorder(t, config)
+	_, span := Tracer("test").Start(context.Background(), "operation")
+	span.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d spans, want 1", len(ended))
+	}
+
+	return ended[0].Resource()
+}
+
+func TestInitResourceAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		resourceEnv string
+		config      Config
+		want        map[attribute.Key]string
+	}{
+		{
+			name:   "attributes",
+			config: Config{Attributes: []attribute.KeyValue{attribute.String("deployment.environment", "staging"), attribute.String("team", "payments")}},
+			want:   map[attribute.Key]string{"deployment.environment": "staging", "team": "payments"},
+		},
+		{
+			name:   "attribute map merges with attributes",
+			config: Config{Attributes: []attribute.KeyValue{attribute.String("env", "prod")}, AttributeMap: map[string]string{"team": "payments", "region": "eu"}},
+			want:   map[attribute.Key]string{"env": "prod", "team": "payments", "region": "eu"},
+		},
+		{
+			name:   "last value of a repeated key wins",
+			config: Config{Attributes: []attribute.KeyValue{attribute.String("env", "dev"), attribute.String("team", "orders"), attribute.String("env", "prod")}},
+			want:   map[attribute.Key]string{"env": "prod", "team": "orders"},
+		},
+		{
+			name:   "service version",
+			config: Config{ServiceVersion: "1.2.3"},
+			want:   map[attribute.Key]string{"service.version": "1.2.3"},
+		},
+		{
+			name:        "version from OTEL_RESOURCE_ATTRIBUTES is kept",
+			resourceEnv: "service.version=2.0.0",
+			want:        map[attribute.Key]string{"service.version": "2.0.0"},
+		},
+		{
+			name: "no version is injected",
+			want: map[attribute.Key]string{"service.version": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", tt.resourceEnv)
+			res := spanResource(t, tt.config)
+
+			for key, want := range tt.want {
+				if got, _ := res.Set().Value(key); got.AsString() != want {
+					t.Errorf("%s = %q, want %q", key, got.AsString(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestInitFlushInterval(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	exporter := tracetest.NewInMemoryExporter()
+
+	_, traceProvider, err := Init(context.Background(), Config{
+		DisableGlobal: true,
+		FlushInterval: 50 * time.Millisecond,
+		ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+			return exporter, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+	_, span := traceProvider.Tracer("test").Start(context.Background(), "operation")
+	span.End()
+
+	// The default batch timeout is 5 seconds, the span must be exported well before it
+	deadline := time.Now().Add(2 * time.Second)
+	for len(exporter.GetSpans()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("span not exported within 2s with a 50ms FlushInterval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// blockedExporter holds every export until release is closed, and keeps the exported spans on
+// Shutdown.
+type blockedExporter struct {
+	*tracetest.InMemoryExporter
+	release chan struct{}
+}
+
+func (e *blockedExporter) Shutdown(context.Context) error { return nil }
+
+func (e *blockedExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	<-e.release
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+func TestInitBatchOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		batchOptions BatchOptions
+		wantDropped  bool
+	}{
+		{name: "default queue", wantDropped: false},
+		{name: "tiny queue", batchOptions: BatchOptions{MaxQueueSize: 2, MaxExportBatchSize: 1}, wantDropped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			exporter := &blockedExporter{InMemoryExporter: tracetest.NewInMemoryExporter(), release: make(chan struct{})}
+
+			_, traceProvider, err := Init(context.Background(), Config{
+				DisableGlobal: true,
+				BatchOptions:  tt.batchOptions,
+				ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+					return exporter, nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The exporter is stalled, so spans pile up in the queue
+			tracer := traceProvider.Tracer("test")
+			for i := 0; i < 20; i++ {
+				_, span := tracer.Start(context.Background(), "operation")
+				span.End()
+			}
+
+			close(exporter.release)
+			if err := shutdown(context.Background(), traceProvider); err != nil {
+				t.Fatal(err)
+			}
+
+			exported := len(exporter.GetSpans())
+			if dropped := exported < 20; dropped != tt.wantDropped {
+				t.Errorf("exported %d of 20 spans, want dropped spans %t", exported, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestInitDisableGlobal(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	for _, disableGlobal := range []bool{true, false} {
+		sentinel := trace.NewTracerProvider()
+		otel.SetTracerProvider(sentinel)
+
+		_, traceProvider, err := Init(context.Background(), Config{DisableGlobal: disableGlobal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+		want := oteltrace.TracerProvider(traceProvider)
+		if disableGlobal {
+			want = sentinel
+		}
+		if got := otel.GetTracerProvider(); got != want {
+			t.Errorf("DisableGlobal %t: global tracer provider = %p, want %p", disableGlobal, got, want)
+		}
+	}
+}
+
+// initExporter initializes tracing with spans exported synchronously to an in-memory exporter and
+// returns the context from Init and the exporter.
+func initExporter(t *testing.T, config Config) (context.Context, *tracetest.InMemoryExporter) {
+	t.Helper()
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	exporter := tracetest.NewInMemoryExporter()
+	config.DisableGlobal = true
+	config.SyncExport = true
+	config.ExporterFactory = func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+		return exporter, nil
+	}
+
+	ctx, traceProvider, err := Init(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background(), traceProvider) })
+
+	return ctx, exporter
+}
+
+// exportedSpan returns the span exported after starting and ending one with opts on a provider
+// initialized with config.
+func exportedSpan(t *testing.T, config Config, opts ...oteltrace.SpanStartOption) tracetest.SpanStub {
+	t.Helper()
+
+	ctx, exporter := initExporter(t, config)
+	_, span := TracerFromContext(ctx, "test").Start(context.Background(), "operation", opts...)
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+
+	return spans[0]
+}
+
+func TestInitDisabledDetectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		disabled  common.ResourceDetectors
+		attribute attribute.Key
+	}{
+		{name: "host", disabled: common.ResourceDetectors{Host: true}, attribute: "host.name"},
+		{name: "process", disabled: common.ResourceDetectors{Process: true}, attribute: "process.command_args"},
+		{name: "os", disabled: common.ResourceDetectors{OS: true}, attribute: "os.type"},
+		{name: "telemetry sdk", disabled: common.ResourceDetectors{TelemetrySDK: true}, attribute: "telemetry.sdk.name"},
+		{name: "environment", disabled: common.ResourceDetectors{FromEnv: true}, attribute: "deployment.tier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.tier=backend")
+
+			if set := exportedSpan(t, Config{}).Resource.Set(); !set.HasValue(tt.attribute) {
+				t.Fatalf("%s is missing with all detectors enabled", tt.attribute)
+			}
+
+			config := Config{ResourceConfig: common.ResourceConfig{DisabledDetectors: tt.disabled}}
+			if set := exportedSpan(t, config).Resource.Set(); set.HasValue(tt.attribute) {
+				t.Errorf("%s is present with the detector disabled", tt.attribute)
+			}
+		})
+	}
+}
+
+func TestInitSpanLimits(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("a", "0123456789"),
+		attribute.String("b", "0123456789"),
+		attribute.String("c", "0123456789"),
+	}
+
+	tests := []struct {
+		name        string
+		limits      *trace.SpanLimits
+		wantCount   int
+		wantDropped int
+		wantLength  int
+	}{
+		{name: "default", wantCount: 3, wantLength: 10},
+		{name: "attribute count", limits: SpanLimitsWithAttributes(2, -1), wantCount: 2, wantDropped: 1, wantLength: 10},
+		{name: "value length", limits: SpanLimitsWithAttributes(128, 4), wantCount: 3, wantLength: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := exportedSpan(t, Config{SpanLimits: tt.limits}, oteltrace.WithAttributes(attrs...))
+
+			if len(span.Attributes) != tt.wantCount || span.DroppedAttributes != tt.wantDropped {
+				t.Fatalf("exported %d attributes with %d dropped, want %d with %d dropped", len(span.Attributes), span.DroppedAttributes, tt.wantCount, tt.wantDropped)
+			}
+			for _, attr := range span.Attributes {
+				if got := len(attr.Value.AsString()); got != tt.wantLength {
+					t.Errorf("attribute %s has length %d, want %d", attr.Key, got, tt.wantLength)
+				}
+			}
+		})
+	}
+}
+
+func TestInitSyncExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		syncExport bool
+		want       int
+	}{
+		{name: "sync", syncExport: true, want: 1},
+		{name: "batched", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", "")
+			t.Setenv("OTEL_BSP_SCHEDULE_DELAY", "")
+
+			exporter := tracetest.NewInMemoryExporter()
+			_, traceProvider, err := Init(context.Background(), Config{
+				DisableGlobal: true,
+				SyncExport:    tt.syncExport,
+				ExporterFactory: func(context.Context, *tls.Config) (trace.SpanExporter, error) {
+					return exporter, nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = shutdown(context.Background(), traceProvider) }()
+
+			_, span := traceProvider.Tracer("test").Start(context.Background(), "operation")
+			span.End()
+
+			if got := len(exporter.GetSpans()); got != tt.want {
+				t.Errorf("exported %d spans before Shutdown, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// stubIDGenerator returns fixed trace and span IDs.
+type stubIDGenerator struct{}
+
+var (
+	stubTraceID = oteltrace.TraceID{0x5f, 0x46, 0x7f, 0xe1, 0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8, 0x9, 0xa, 0xb, 0xc}
+	stubSpanID  = oteltrace.SpanID{0xd, 0xe, 0xf, 0x1, 0x2, 0x3, 0x4, 0x5}
+)
+
+func (stubIDGenerator) NewIDs(context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+	return stubTraceID, stubSpanID
+}
+
+func (stubIDGenerator) NewSpanID(context.Context, oteltrace.TraceID) oteltrace.SpanID {
+	return stubSpanID
+}
+
+func TestInitIDGenerator(t *testing.T) {
+	span := exportedSpan(t, Config{IDGenerator: stubIDGenerator{}})
+
+	if span.SpanContext.TraceID() != stubTraceID || span.SpanContext.SpanID() != stubSpanID {
+		t.Errorf("span ids = %s/%s, want %s/%s from the generator", span.SpanContext.TraceID(), span.SpanContext.SpanID(), stubTraceID, stubSpanID)
+	}
+}
+
+func TestShutdownWithTimeoutStalledCollector(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stalled:
+		}
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+
+	_, traceProvider, err := Init(context.Background(), Config{DisableGlobal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := traceProvider.Tracer("test").Start(context.Background(), "operation")
+	span.End()
+
+	start := time.Now()
+	err = ShutdownWithTimeout(context.Background(), traceProvider, 200*time.Millisecond)
+	if !errors.Is(err, common.ErrShutdownTimeout) {
+		t.Errorf("ShutdownWithTimeout() = %v, want ErrShutdownTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ShutdownWithTimeout returned after %s, want no later than the 200ms deadline", elapsed)
+	}
+}
+
+func TestInitResource(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+
+	res := resource.NewSchemaless(attribute.String("service.name", "orders"), attribute.String("team", "payments"))
+	config := Config{
+		Resource:   res,
+		Attributes: []attribute.KeyValue{attribute.String("ignored", "true")},
+	}
+
+	if got := exportedSpan(t, config).Resource; !got.Equal(res) {
+		t.Errorf("span resource = %v, want the injected %v", got.Attributes(), res.Attributes())
+	}
+}
+
+func TestInitServiceGroup(t *testing.T) {
+	tests := []struct {
+		name  string
+		group string
+		env   string
+		want  string
+	}{
+		{name: "config", group: "checkout", want: "checkout"},
+		{name: "env fallback", env: "billing", want: "billing"},
+		{name: "config wins over env", group: "checkout", env: "billing", want: "checkout"},
+		{name: "unset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_SERVICE_GROUP", tt.env)
+
+			res := exportedSpan(t, Config{ServiceGroup: tt.group}).Resource
+			if got, _ := res.Set().Value(common.ServiceGroupKey); got.AsString() != tt.want {
+				t.Errorf("span resource service.group = %q, want %q", got.AsString(), tt.want)
+			}
+			if tt.want == "" && res.Set().HasValue(common.ServiceGroupKey) {
+				t.Errorf("span resource has service.group without a group set")
+			}
+		})
+	}
+}